@@ -33,6 +33,7 @@ const (
 	EfreeToneMarking uint = 1 << iota
 	EstdToneStyle
 	EautoCorrectEnabled
+	EquickTelex
 	EstdFlags = EfreeToneMarking | EstdToneStyle | EautoCorrectEnabled
 )
 
@@ -46,12 +47,15 @@ type IEngine interface {
 	SetFlag(uint)
 	GetInputMethod() InputMethod
 	ProcessKey(rune, Mode)
+	ProcessKeyWithModifiers(rune, Modifier, Mode)
 	ProcessString(string, Mode)
 	GetProcessedString(Mode) string
 	GetSpellingMatchResult(Mode, bool) uint8
 	CanProcessKey(rune) bool
+	CanProcessKeyWithModifiers(rune, Modifier) bool
 	RemoveLastChar()
 	RestoreLastWord()
+	ReplaceLastWord(string)
 	GetRawString() string
 	Reset()
 }
@@ -122,24 +126,62 @@ func (e *BambooEngine) GetProcessedString(mode Mode) string {
 	return ""
 }
 
-func (e *BambooEngine) getApplicableRules(key rune) []Rule {
+// getApplicableRules returns the rules of the key held with the modifiers. The
+// rules of a key sequence only apply when the keys typed before the key match
+// the sequence, and then the longest matching sequence wins, e.g. "uow" over
+// "ow" over "w".
+func (e *BambooEngine) getApplicableRules(key rune, modifiers Modifier) []Rule {
+	var rawKeys = e.getRawKeys()
 	var applicableRules []Rule
+	var prefixLen = 0
 	for _, inputRule := range e.inputMethod.Rules {
-		if inputRule.Key == unicode.ToLower(key) {
+		if inputRule.Key != unicode.ToLower(key) || inputRule.Modifiers != modifiers || !hasKeyPrefix(rawKeys, inputRule.Prefix) {
+			continue
+		}
+		if len(inputRule.Prefix) > prefixLen {
+			applicableRules = nil
+			prefixLen = len(inputRule.Prefix)
+		}
+		if len(inputRule.Prefix) == prefixLen {
 			applicableRules = append(applicableRules, inputRule)
 		}
 	}
 	return applicableRules
 }
 
+// getRawKeys returns the keys typed so far in lower case, virtual keys left out.
+func (e *BambooEngine) getRawKeys() []rune {
+	var keys []rune
+	for _, t := range e.composition {
+		if t.Rule.Key != 0 {
+			keys = append(keys, unicode.ToLower(t.Rule.Key))
+		}
+	}
+	return keys
+}
+
 func (e *BambooEngine) findTargetByKey(composition []*Transformation, key rune) (*Transformation, Rule) {
-	return findTarget(composition, e.getApplicableRules(key), e.flags)
+	return findTarget(composition, e.getApplicableRules(key, 0), e.flags)
 }
 
 func (e *BambooEngine) CanProcessKey(key rune) bool {
 	return e.isSupportedKey(key)
 }
 
+// CanProcessKeyWithModifiers tells whether the key held with the modifiers is
+// bound, a key without modifiers works as in CanProcessKey.
+func (e *BambooEngine) CanProcessKeyWithModifiers(key rune, modifiers Modifier) bool {
+	if modifiers == 0 {
+		return e.CanProcessKey(key)
+	}
+	for _, rule := range e.inputMethod.Rules {
+		if rule.Key == unicode.ToLower(key) && rule.Modifiers == modifiers {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *BambooEngine) ProcessString(str string, mode Mode) {
 	for _, key := range []rune(str) {
 		e.ProcessKey(key, mode)
@@ -147,9 +189,16 @@ func (e *BambooEngine) ProcessString(str string, mode Mode) {
 }
 
 func (e *BambooEngine) ProcessKey(key rune, mode Mode) {
+	e.ProcessKeyWithModifiers(key, 0, mode)
+}
+
+// ProcessKeyWithModifiers processes a key held with the modifiers, e.g. the d
+// of AltGr+d. The composition only keeps the key, so a key which was held
+// with modifiers reads as the bare key in the raw string.
+func (e *BambooEngine) ProcessKeyWithModifiers(key rune, modifiers Modifier, mode Mode) {
 	var lowerKey = unicode.ToLower(key)
 	var isUpperCase = unicode.IsUpper(key)
-	if mode&EnglishMode != 0 || !e.isSupportedKey(lowerKey) {
+	if mode&EnglishMode != 0 || !e.CanProcessKeyWithModifiers(lowerKey, modifiers) {
 		e.composition = append(e.composition, newAppendingTrans(lowerKey, isUpperCase))
 		return
 	}
@@ -157,18 +206,25 @@ func (e *BambooEngine) ProcessKey(key rune, mode Mode) {
 	var lastSyllable, previousTransformations = extractLastSyllable(e.composition)
 
 	// Find all possible transformations this keypress can generate
-	lastSyllable = append(lastSyllable, e.generateTransformations(lastSyllable, lowerKey, isUpperCase)...)
+	lastSyllable = append(lastSyllable, e.generateTransformations(lastSyllable, lowerKey, modifiers, isUpperCase)...)
 
 	// Put these transformations back to the composition
 	e.composition = append(previousTransformations, lastSyllable...)
 }
 
-func (e *BambooEngine) generateTransformations(composition []*Transformation, lowerKey rune, isUpperCase bool) []*Transformation {
-	var transformations = generateTransformations(composition, e.getApplicableRules(lowerKey), e.flags, lowerKey, isUpperCase)
+func (e *BambooEngine) generateTransformations(composition []*Transformation, lowerKey rune, modifiers Modifier, isUpperCase bool) []*Transformation {
+	var applicableRules = e.getApplicableRules(lowerKey, modifiers)
+	var transformations []*Transformation
+	if e.flags&EquickTelex != 0 && modifiers == 0 {
+		transformations = generateQuickTelexTrans(composition, lowerKey, isUpperCase)
+	}
+	if transformations == nil {
+		transformations = generateTransformations(composition, applicableRules, e.flags, lowerKey, isUpperCase)
+	}
 	if transformations == nil {
 		// If none of the applicable_rules can actually be applied then this new
 		// transformation fall-backs to an APPENDING one.
-		transformations = generateFallbackTransformations(e.getApplicableRules(lowerKey), lowerKey, isUpperCase)
+		transformations = generateFallbackTransformations(applicableRules, lowerKey, isUpperCase)
 		var newComposition = append(composition, transformations...)
 
 		// Implement the uow typing shortcut by creating a virtual
@@ -220,6 +276,13 @@ func (e *BambooEngine) RestoreLastWord() {
 	e.composition = append(previous, breakComposition(lastComb)...)
 }
 
+// ReplaceLastWord swaps the last word of the composition for the given one,
+// e.g. a variant chosen from GenerateVariants.
+func (e *BambooEngine) ReplaceLastWord(word string) {
+	var _, previous = extractLastWord(e.composition, e.inputMethod.Keys)
+	e.composition = append(previous, e.buildComposition(word)...)
+}
+
 func (e *BambooEngine) Reset() {
 	e.composition = nil
 }
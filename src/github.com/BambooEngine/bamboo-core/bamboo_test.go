@@ -571,3 +571,100 @@ func TestDoubleTyping(t *testing.T) {
 		t.Errorf("Findresultmatch full, got %d expected true", ng.GetSpellingMatchResult(VietnameseMode, true))
 	}
 }
+
+func BenchmarkParseInputMethods(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		parseInputMethods(InputMethodDefinitions)
+	}
+}
+
+func BenchmarkParseInputMethod(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ParseInputMethod(InputMethodDefinitions, "Telex")
+	}
+}
+
+func BenchmarkNewEngineWithParsing(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var im = parseInputMethods(InputMethodDefinitions)["Telex"]
+		NewEngine(im, EstdFlags)
+	}
+}
+
+func BenchmarkNewEngineWithCache(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var im = LookupInputMethod("Telex", InputMethodDefinitions["Telex"])
+		NewEngine(im, EstdFlags)
+	}
+}
+
+func TestQuickTelex(t *testing.T) {
+	var ng = NewEngine(ParseInputMethod(InputMethodDefinitions, "Telex"), EstdFlags|EquickTelex)
+	var tests = map[string]string{
+		"ccaf":   "chà",
+		"ggaf":   "già",
+		"kkoong": "không",
+		"nnaf":   "ngà",
+		"ppowr":  "phở",
+		"qqaf":   "quà",
+		"ttuw":   "thư",
+		"mann":   "mang",
+		"Ccaf":   "Chà",
+		"CCAF":   "CHÀ",
+		"ccc":    "cc",
+	}
+	for input, expected := range tests {
+		ng.Reset()
+		ng.ProcessString(input, VietnameseMode)
+		if ng.GetProcessedString(VietnameseMode) != expected {
+			t.Errorf("Process [%s] with Quick Telex, expected [%s], got [%s]", input, expected, ng.GetProcessedString(VietnameseMode))
+		}
+	}
+	ng.SetFlag(EstdFlags)
+	ng.Reset()
+	ng.ProcessString("ccaf", VietnameseMode)
+	if ng.GetProcessedString(VietnameseMode) != "ccà" {
+		t.Errorf("Process [ccaf] without Quick Telex, expected [ccà], got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+}
+
+func TestQuickTelexWithVNI(t *testing.T) {
+	var ng = NewEngine(ParseInputMethod(InputMethodDefinitions, "VNI"), EstdFlags|EquickTelex)
+	ng.ProcessString("nna2", VietnameseMode)
+	if ng.GetProcessedString(VietnameseMode) != "ngà" {
+		t.Errorf("Process [nna2] with VNI and Quick Telex, expected [ngà], got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+}
+
+func TestQuickTelexRawKeys(t *testing.T) {
+	var ng = NewEngine(ParseInputMethod(InputMethodDefinitions, "Telex"), EstdFlags|EquickTelex)
+	ng.ProcessString("kkoong", VietnameseMode)
+	if ng.GetRawString() != "kkoong" {
+		t.Errorf("Raw string of [kkoong], got [%s]", ng.GetRawString())
+	}
+	if ng.GetProcessedString(EnglishMode) != "kkoong" {
+		t.Errorf("English mode of [kkoong], got [%s]", ng.GetProcessedString(EnglishMode))
+	}
+	if ng.GetSpellingMatchResult(ToneLess|LowerCase, false) != FindResultMatchFull {
+		t.Errorf("Spelling of [không], expected a full match")
+	}
+	ng.RestoreLastWord()
+	if ng.GetProcessedString(VietnameseMode) != "kkoong" {
+		t.Errorf("Restore [không], expected [kkoong], got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+	ng.Reset()
+	ng.ProcessString("kk", VietnameseMode)
+	ng.RemoveLastChar()
+	if ng.GetProcessedString(VietnameseMode) != "k" || ng.GetRawString() != "k" {
+		t.Errorf("Remove the last char of [kh], expected [k], got [%s] raw [%s]", ng.GetProcessedString(VietnameseMode), ng.GetRawString())
+	}
+	ng.ProcessString("k", VietnameseMode)
+	if ng.GetProcessedString(VietnameseMode) != "kh" {
+		t.Errorf("Process [k] after a removal, expected [kh], got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+	ng.Reset()
+	ng.ProcessString("ccuwj", EnglishMode)
+	if ng.GetProcessedString(VietnameseMode) != "ccuwj" {
+		t.Errorf("Process [ccuwj] in English mode, expected the keys as typed, got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+}
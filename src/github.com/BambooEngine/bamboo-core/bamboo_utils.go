@@ -91,7 +91,7 @@ func getCombinationWithSound(composition []*Transformation) ([]*Transformation,
 		return lastComb, nil
 	}
 	var str = Flatten(lastComb, VietnameseMode|ToneLess|LowerCase)
-	if TestString(spellingTrie, []rune(str), false) != FindResultNotMatch {
+	if testSpelling([]rune(str), false) != FindResultNotMatch {
 		return lastComb, ParseSoundsFromWord(str)
 	}
 	return lastComb, ParseSoundsFromWord(str)
@@ -121,7 +121,7 @@ func getSpellingMatchResult(composition []*Transformation, mode Mode, dictionary
 	if len(chars) <= 1 {
 		return FindResultMatchFull
 	}
-	return TestString(spellingTrie, chars, dictionary)
+	return testSpelling(chars, dictionary)
 }
 
 func getRightMostVowels(composition []*Transformation) []*Transformation {
@@ -272,7 +272,7 @@ func getLastSyllable(composition []*Transformation) []*Transformation {
 		if str == "" {
 			continue
 		}
-		if TestString(spellingTrie, []rune(str), false) == FindResultNotMatch {
+		if testSpelling([]rune(str), false) == FindResultNotMatch {
 			if i == 0 {
 				return getLastSyllable(composition[1:])
 			}
@@ -0,0 +1,311 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A bigram model is stored as plain text, one line per word:
+//
+//	# comment
+//	cảm<TAB>ơn 120<TAB>thấy 45
+//	hà<TAB>Nội 310<TAB>Giang 12
+//
+// The first field is the lower-case word, the next ones are the words which
+// follow it with their counts, the most frequent first.
+//
+// In memory the words are stored once in a sorted vocabulary and a word pair
+// takes 8 bytes, so that a model of a few hundred thousand pairs fits in a
+// few megabytes.
+
+// BigramModel is read-only once built, it is safe for concurrent use.
+type BigramModel struct {
+	// the vocabulary, sorted, in a single string: the word i ends at ends[i]
+	vocabulary string
+	ends       []uint32
+	// the pairs of the word i are pairs[first[i]:first[i+1]], the most
+	// frequent first
+	first []uint32
+	pairs []bigramPair
+	// how many times each word follows another one, by the id of its
+	// lower-case form which is always in the vocabulary
+	frequencies []uint32
+}
+
+type bigramPair struct {
+	next  uint32
+	count uint32
+}
+
+func NewBigramModel() *BigramModel {
+	return &BigramModel{first: []uint32{0}}
+}
+
+func (m *BigramModel) word(id uint32) string {
+	var start uint32
+	if id > 0 {
+		start = m.ends[id-1]
+	}
+	return m.vocabulary[start:m.ends[id]]
+}
+
+// lookup returns the id of the word in the vocabulary.
+func (m *BigramModel) lookup(word string) (uint32, bool) {
+	var i = sort.Search(len(m.ends), func(i int) bool {
+		return m.word(uint32(i)) >= word
+	})
+	return uint32(i), i < len(m.ends) && m.word(uint32(i)) == word
+}
+
+// following returns the pairs of the lower-case form of the word.
+func (m *BigramModel) following(word string) []bigramPair {
+	var id, found = m.lookup(strings.ToLower(word))
+	if !found {
+		return nil
+	}
+	return m.pairs[m.first[id]:m.first[id+1]]
+}
+
+// Len returns the number of word pairs of the model.
+func (m *BigramModel) Len() int {
+	return len(m.pairs)
+}
+
+// Predict returns at most limit words which are likely to follow the given
+// word, e.g. "cảm" -> "ơn".
+func (m *BigramModel) Predict(word string, limit int) []string {
+	var pairs = m.following(word)
+	if limit > 0 && len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	var words = make([]string, len(pairs))
+	for i, p := range pairs {
+		words[i] = m.word(p.next)
+	}
+	return words
+}
+
+// Count returns how many times next follows word, in any case.
+func (m *BigramModel) Count(word, next string) int {
+	next = strings.ToLower(next)
+	var count = 0
+	for _, p := range m.following(word) {
+		if strings.ToLower(m.word(p.next)) == next {
+			count += int(p.count)
+		}
+	}
+	return count
+}
+
+// Frequency returns how many times the word follows any other word, it tells
+// the common words from the rare ones.
+func (m *BigramModel) Frequency(word string) int {
+	if id, found := m.lookup(strings.ToLower(word)); found {
+		return int(m.frequencies[id])
+	}
+	return 0
+}
+
+// bigramCounter counts the word pairs while a model is read or built.
+type bigramCounter struct {
+	next    map[string]map[string]int
+	entries int
+}
+
+func newBigramCounter() *bigramCounter {
+	return &bigramCounter{next: map[string]map[string]int{}}
+}
+
+func (c *bigramCounter) add(word, next string, count int) {
+	var counts = c.next[word]
+	if counts == nil {
+		counts = map[string]int{}
+		c.next[word] = counts
+	}
+	if _, found := counts[next]; !found {
+		c.entries++
+	}
+	counts[next] += count
+}
+
+// prune keeps the budget most frequent word pairs.
+func (c *bigramCounter) prune(budget int) {
+	if budget <= 0 || c.entries <= budget {
+		return
+	}
+	var counts = make([]int, 0, c.entries)
+	for _, nexts := range c.next {
+		for _, count := range nexts {
+			counts = append(counts, count)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	var threshold = counts[budget-1]
+	// pairs with the threshold count are kept while there is room for them
+	var room = budget
+	for _, count := range counts[:budget] {
+		if count > threshold {
+			room--
+		}
+	}
+	c.entries = 0
+	for word, nexts := range c.next {
+		for next, count := range nexts {
+			if count > threshold || (count == threshold && room > 0) {
+				if count == threshold {
+					room--
+				}
+				c.entries++
+			} else {
+				delete(nexts, next)
+			}
+		}
+		if len(nexts) == 0 {
+			delete(c.next, word)
+		}
+	}
+}
+
+// model lays the counted pairs out in a BigramModel.
+func (c *bigramCounter) model() *BigramModel {
+	var vocabulary = map[string]uint32{}
+	for word, nexts := range c.next {
+		vocabulary[word] = 0
+		for next := range nexts {
+			vocabulary[next] = 0
+			vocabulary[strings.ToLower(next)] = 0
+		}
+	}
+	var words = make([]string, 0, len(vocabulary))
+	for word := range vocabulary {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	var m = &BigramModel{
+		ends:        make([]uint32, len(words)),
+		first:       make([]uint32, len(words)+1),
+		pairs:       make([]bigramPair, 0, c.entries),
+		frequencies: make([]uint32, len(words)),
+	}
+	var text strings.Builder
+	for i, word := range words {
+		vocabulary[word] = uint32(i)
+		text.WriteString(word)
+		m.ends[i] = uint32(text.Len())
+	}
+	m.vocabulary = text.String()
+	for i, word := range words {
+		m.first[i] = uint32(len(m.pairs))
+		for next, count := range c.next[word] {
+			m.pairs = append(m.pairs, bigramPair{vocabulary[next], uint32(count)})
+			m.frequencies[vocabulary[strings.ToLower(next)]] += uint32(count)
+		}
+		var pairs = m.pairs[m.first[i]:]
+		// the ties are broken by the vocabulary order, which is the order of
+		// the words
+		sort.Slice(pairs, func(i, j int) bool {
+			if pairs[i].count != pairs[j].count {
+				return pairs[i].count > pairs[j].count
+			}
+			return pairs[i].next < pairs[j].next
+		})
+	}
+	m.first[len(words)] = uint32(len(m.pairs))
+	return m
+}
+
+// LoadBigramModel reads a model in the text format above. At most budget word
+// pairs are kept in memory (no limit when budget is 0), the least frequent
+// pairs are dropped first.
+func LoadBigramModel(r io.Reader, budget int) (*BigramModel, error) {
+	var c = newBigramCounter()
+	var scanner = bufio.NewScanner(r)
+	var lineNumber = 0
+	for scanner.Scan() {
+		lineNumber++
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var fields = strings.Split(line, "\t")
+		var word = strings.ToLower(fields[0])
+		for _, field := range fields[1:] {
+			var i = strings.LastIndexByte(field, ' ')
+			if i <= 0 {
+				return nil, fmt.Errorf("line %d: malformed prediction %q", lineNumber, field)
+			}
+			var count, err = strconv.Atoi(field[i+1:])
+			if err != nil || count <= 0 {
+				return nil, fmt.Errorf("line %d: malformed count in %q", lineNumber, field)
+			}
+			c.add(word, field[:i], count)
+		}
+		// prune from time to time so that the memory stays around the budget
+		if budget > 0 && c.entries >= 2*budget {
+			c.prune(budget)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	c.prune(budget)
+	return c.model(), nil
+}
+
+// CountBigrams builds a model from a plain text corpus, the word pairs are
+// counted inside a sentence only.
+func CountBigrams(corpus io.Reader) (*BigramModel, error) {
+	var c = newBigramCounter()
+	var scanner = bufio.NewScanner(corpus)
+	var isSentenceBreak = func(r rune) bool {
+		return unicode.IsPunct(r) && r != '\'' && r != '-'
+	}
+	for scanner.Scan() {
+		for _, sentence := range strings.FieldsFunc(scanner.Text(), isSentenceBreak) {
+			var words = strings.Fields(sentence)
+			for i := 1; i < len(words); i++ {
+				c.add(strings.ToLower(words[i-1]), words[i], 1)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c.model(), nil
+}
+
+// WriteTo writes the model in the text format above, the words are sorted.
+func (m *BigramModel) WriteTo(w io.Writer) (int64, error) {
+	var bw = bufio.NewWriter(w)
+	var n int64
+	for id := range m.ends {
+		var pairs = m.pairs[m.first[id]:m.first[id+1]]
+		if len(pairs) == 0 {
+			continue
+		}
+		var line strings.Builder
+		line.WriteString(m.word(uint32(id)))
+		for _, p := range pairs {
+			line.WriteString("\t" + m.word(p.next) + " " + strconv.Itoa(int(p.count)))
+		}
+		line.WriteString("\n")
+		written, err := bw.WriteString(line.String())
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
@@ -0,0 +1,112 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func countFixtureBigrams(t *testing.T) *BigramModel {
+	var f, err = os.Open("testdata/corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := CountBigrams(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestBigramPredict(t *testing.T) {
+	var m = countFixtureBigrams(t)
+	if words := m.Predict("cảm", 0); !reflect.DeepEqual(words, []string{"ơn", "thấy"}) {
+		t.Errorf("Predict [cảm], got %q", words)
+	}
+	if words := m.Predict("Hà", 1); !reflect.DeepEqual(words, []string{"Nội"}) {
+		t.Errorf("Predict [Hà], got %q", words)
+	}
+	if words := m.Predict("nhiều", 0); len(words) != 0 {
+		t.Errorf("Predict [nhiều], expected no pair across sentences, got %q", words)
+	}
+}
+
+func TestBigramCount(t *testing.T) {
+	var m, err = LoadBigramModel(strings.NewReader("cảm\tơn 3\tthấy 1\nrất\tthấy 2\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := m.Count("Cảm", "ơn"); n != 3 {
+		t.Errorf("Count [cảm ơn], expected [3], got [%d]", n)
+	}
+	if n := m.Count("ơn", "cảm"); n != 0 {
+		t.Errorf("Count [ơn cảm], expected [0], got [%d]", n)
+	}
+	if n := m.Frequency("thấy"); n != 3 {
+		t.Errorf("Frequency [thấy], expected [3], got [%d]", n)
+	}
+}
+
+func TestBigramModelRoundTrip(t *testing.T) {
+	var m = countFixtureBigrams(t)
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBigramModel(&buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != m.Len() || !reflect.DeepEqual(loaded.Predict("hà", 0), m.Predict("hà", 0)) {
+		t.Errorf("Round trip, expected %d pairs, got %d", m.Len(), loaded.Len())
+	}
+}
+
+func TestLoadBigramModelBudget(t *testing.T) {
+	var model = "# fixture\ncảm\tơn 10\tthấy 3\nhà\tNội 8\tGiang 1\ntôi\tsống 2\n"
+	m, err := LoadBigramModel(strings.NewReader(model), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 3 {
+		t.Errorf("Budget of 3 pairs, got %d", m.Len())
+	}
+	if words := m.Predict("cảm", 0); !reflect.DeepEqual(words, []string{"ơn", "thấy"}) {
+		t.Errorf("Predict [cảm] with a budget, got %q", words)
+	}
+	if words := m.Predict("hà", 0); !reflect.DeepEqual(words, []string{"Nội"}) {
+		t.Errorf("Predict [hà] with a budget, got %q", words)
+	}
+	if _, err := LoadBigramModel(strings.NewReader("cảm\tơn\n"), 0); err == nil {
+		t.Errorf("Load a malformed model, expected an error")
+	}
+}
+
+func TestBigramModelCase(t *testing.T) {
+	var m, err = LoadBigramModel(strings.NewReader("hà\tNội 3\tnội 2\tGiang 1\nở\tHà 4\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if words := m.Predict("HÀ", 0); !reflect.DeepEqual(words, []string{"Nội", "nội", "Giang"}) {
+		t.Errorf("Predict [HÀ], got %q", words)
+	}
+	if n := m.Count("hà", "NỘI"); n != 5 {
+		t.Errorf("Count [hà nội] in any case, expected [5], got [%d]", n)
+	}
+	if n := m.Frequency("hà"); n != 4 {
+		t.Errorf("Frequency [hà], expected [4], got [%d]", n)
+	}
+	if words := m.Predict("nội", 0); len(words) != 0 || NewBigramModel().Len() != 0 {
+		t.Errorf("Predict a word which is only followed, got %q", words)
+	}
+}
@@ -0,0 +1,266 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// A compact trie is a read-only trie laid out in a single byte slice, so that
+// it can be generated at build time and mapped into memory as is:
+//
+//	header: "BTRI" version:uint16 0:uint16 nodes:uint32 edges:uint32
+//	nodes:  firstEdge:uint32 edgeCount:uint16 flags:uint16, the root first
+//	edges:  char:uint32 child:uint32, sorted by char for each node
+//
+// All the numbers are little endian.
+
+const CompactTrieVersion = 1
+
+const (
+	compactTrieHeaderSize = 16
+	compactNodeSize       = 8
+	compactEdgeSize       = 8
+
+	compactFlagFull       = 1
+	compactFlagDictionary = 2
+)
+
+var compactTrieMagic = []byte("BTRI")
+
+var ErrCompactTrieFormat = errors.New("malformed compact trie")
+
+type CompactTrie struct {
+	nodes []byte
+	edges []byte
+	close func() error
+}
+
+// CompactNode is a node of a compact trie, see Node.
+type CompactNode struct {
+	trie  *CompactTrie
+	index uint32
+}
+
+// EncodeCompactTrie lays the trie out in the compact format.
+func EncodeCompactTrie(trie *Node) ([]byte, error) {
+	// the nodes are numbered breadth first, so that the children of a node
+	// are next to each other
+	var queue = []*Node{trie}
+	var edgeCount = 0
+	for i := 0; i < len(queue); i++ {
+		if len(queue[i].Children) > 0xffff {
+			return nil, fmt.Errorf("%w: a node has %d children", ErrCompactTrieFormat, len(queue[i].Children))
+		}
+		for _, chr := range sortedChildren(queue[i]) {
+			queue = append(queue, queue[i].Children[chr])
+		}
+		edgeCount += len(queue[i].Children)
+	}
+	var data = make([]byte, compactTrieHeaderSize+len(queue)*compactNodeSize+edgeCount*compactEdgeSize)
+	copy(data, compactTrieMagic)
+	binary.LittleEndian.PutUint16(data[4:], CompactTrieVersion)
+	binary.LittleEndian.PutUint32(data[8:], uint32(len(queue)))
+	binary.LittleEndian.PutUint32(data[12:], uint32(edgeCount))
+	var nodes = data[compactTrieHeaderSize:]
+	var edges = nodes[len(queue)*compactNodeSize:]
+	var edge, child = 0, 1
+	for i, node := range queue {
+		var flags uint16
+		if node.Full {
+			flags |= compactFlagFull
+		}
+		if node.Dictionary {
+			flags |= compactFlagDictionary
+		}
+		binary.LittleEndian.PutUint32(nodes[i*compactNodeSize:], uint32(edge))
+		binary.LittleEndian.PutUint16(nodes[i*compactNodeSize+4:], uint16(len(node.Children)))
+		binary.LittleEndian.PutUint16(nodes[i*compactNodeSize+6:], flags)
+		for _, chr := range sortedChildren(node) {
+			binary.LittleEndian.PutUint32(edges[edge*compactEdgeSize:], uint32(chr))
+			binary.LittleEndian.PutUint32(edges[edge*compactEdgeSize+4:], uint32(child))
+			edge++
+			child++
+		}
+	}
+	return data, nil
+}
+
+func sortedChildren(node *Node) []rune {
+	var chars = make([]rune, 0, len(node.Children))
+	for chr := range node.Children {
+		chars = append(chars, chr)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+	return chars
+}
+
+// NewCompactTrie reads a trie in the compact format, the data is used in place
+// and must not be modified.
+func NewCompactTrie(data []byte) (*CompactTrie, error) {
+	if len(data) < compactTrieHeaderSize || string(data[:4]) != string(compactTrieMagic) {
+		return nil, ErrCompactTrieFormat
+	}
+	if version := binary.LittleEndian.Uint16(data[4:]); version != CompactTrieVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCompactTrieFormat, version)
+	}
+	// the counts are untrusted, the size is computed in 64 bits so that it
+	// cannot overflow on 32-bit builds
+	var nodeCount64 = uint64(binary.LittleEndian.Uint32(data[8:]))
+	var edgeCount64 = uint64(binary.LittleEndian.Uint32(data[12:]))
+	if nodeCount64 == 0 || uint64(len(data)) != compactTrieHeaderSize+nodeCount64*compactNodeSize+edgeCount64*compactEdgeSize {
+		return nil, fmt.Errorf("%w: unexpected size", ErrCompactTrieFormat)
+	}
+	var nodeCount, edgeCount = int(nodeCount64), int(edgeCount64)
+	var nodesEnd = compactTrieHeaderSize + nodeCount*compactNodeSize
+	var trie = &CompactTrie{
+		nodes: data[compactTrieHeaderSize:nodesEnd],
+		edges: data[nodesEnd:],
+	}
+	// the lookups trust the offsets, a broken file must not send them out of
+	// the data
+	for i := 0; i < nodeCount; i++ {
+		var node = CompactNode{trie, uint32(i)}
+		var firstEdge = uint64(binary.LittleEndian.Uint32(trie.nodes[i*compactNodeSize:]))
+		if firstEdge+uint64(node.Len()) > edgeCount64 {
+			return nil, fmt.Errorf("%w: the edges of node %d are out of range", ErrCompactTrieFormat, i)
+		}
+	}
+	for i := 0; i < edgeCount; i++ {
+		if child := binary.LittleEndian.Uint32(trie.edges[i*compactEdgeSize+4:]); uint64(child) >= nodeCount64 {
+			return nil, fmt.Errorf("%w: the child of edge %d is out of range", ErrCompactTrieFormat, i)
+		}
+	}
+	return trie, nil
+}
+
+// Close releases the memory the trie was mapped from, the trie must not be
+// used afterwards, nor be in use by a lookup of another goroutine.
+func (t *CompactTrie) Close() error {
+	if t.close == nil {
+		return nil
+	}
+	var err = t.close()
+	t.nodes, t.edges, t.close = nil, nil, nil
+	return err
+}
+
+// Len returns the number of nodes.
+func (t *CompactTrie) Len() int {
+	return len(t.nodes) / compactNodeSize
+}
+
+func (t *CompactTrie) root() CompactNode {
+	return CompactNode{trie: t}
+}
+
+func (n CompactNode) flags() uint16 {
+	return binary.LittleEndian.Uint16(n.trie.nodes[n.index*compactNodeSize+6:])
+}
+
+func (n CompactNode) Full() bool {
+	return n.flags()&compactFlagFull != 0
+}
+
+func (n CompactNode) Dictionary() bool {
+	return n.flags()&compactFlagDictionary != 0
+}
+
+// Len returns the number of children.
+func (n CompactNode) Len() int {
+	return int(binary.LittleEndian.Uint16(n.trie.nodes[n.index*compactNodeSize+4:]))
+}
+
+func (n CompactNode) firstEdge() int {
+	return int(binary.LittleEndian.Uint32(n.trie.nodes[n.index*compactNodeSize:]))
+}
+
+func (n CompactNode) edge(i int) (rune, CompactNode) {
+	var edge = n.trie.edges[(n.firstEdge()+i)*compactEdgeSize:]
+	return rune(binary.LittleEndian.Uint32(edge)), CompactNode{n.trie, binary.LittleEndian.Uint32(edge[4:])}
+}
+
+// Child looks the child up by a binary search over the sorted edges.
+func (n CompactNode) Child(chr rune) (CompactNode, bool) {
+	var count = n.Len()
+	var i = sort.Search(count, func(i int) bool {
+		var c, _ = n.edge(i)
+		return c >= chr
+	})
+	if i < count {
+		if c, child := n.edge(i); c == chr {
+			return child, true
+		}
+	}
+	return CompactNode{}, false
+}
+
+// TestString works like the TestString of a Node trie.
+func (t *CompactTrie) TestString(s []rune, dictionary bool) uint8 {
+	var node = t.root()
+	for _, chr := range s {
+		var child, found = node.Child(unicode.ToLower(chr))
+		if !found {
+			return FindResultNotMatch
+		}
+		node = child
+	}
+	return matchResult(node.Full(), node.Dictionary(), dictionary)
+}
+
+// matchResult is the result of a lookup which ends on a node with the given
+// flags.
+func matchResult(full, inDictionary, dictionary bool) uint8 {
+	if dictionary {
+		if full && inDictionary {
+			return FindResultMatchFull
+		}
+		return FindResultNotMatch
+	}
+	if full {
+		return FindResultMatchFull
+	}
+	return FindResultMatchPrefix
+}
+
+// FindNode works like the FindNode of a Node trie, found is false when there is
+// no node for s.
+func (t *CompactTrie) FindNode(s []rune) (node CompactNode, found bool) {
+	node = t.root()
+	for _, chr := range s {
+		if node, found = node.Child(chr); !found {
+			return node, false
+		}
+	}
+	return node, true
+}
+
+// FindWords lists the full words which start with s.
+func (t *CompactTrie) FindWords(s string) []string {
+	var node, found = t.FindNode([]rune(s))
+	if !found {
+		return nil
+	}
+	var words []string
+	var walk func(node CompactNode, prefix []rune)
+	walk = func(node CompactNode, prefix []rune) {
+		if node.Full() {
+			words = append(words, string(prefix))
+		}
+		for i := 0; i < node.Len(); i++ {
+			var chr, child = node.edge(i)
+			walk(child, append(prefix, chr))
+		}
+	}
+	walk(node, []rune(s))
+	return words
+}
@@ -0,0 +1,50 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+// +build linux darwin freebsd netbsd openbsd
+
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenCompactTrie maps a compact trie file into memory, the pages are shared
+// with the page cache and are only read when a lookup touches them.
+func OpenCompactTrie(fileName string) (*CompactTrie, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < compactTrieHeaderSize {
+		return nil, ErrCompactTrieFormat
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	trie, err := NewCompactTrie(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	// there is no finalizer to unmap the trie: the nodes read the data through
+	// slices which outlive their last use of the trie, and a lookup which
+	// started before a reload may still be running. A replaced trie is left
+	// mapped, the file is small and changes only with the system dictionary.
+	trie.close = func() error {
+		return syscall.Munmap(data)
+	}
+	return trie, nil
+}
@@ -0,0 +1,245 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// every tone and mark variant of the syllables of the default rules, around
+// 55k words, close to the size of the system dictionary
+func generateToneVariantWords() []string {
+	var words []string
+	for _, word := range DefaultSpellingRules.GenerateWords() {
+		words = append(words, GenerateVariants(word, true)...)
+	}
+	return words
+}
+
+func buildNodeTrie(words []string) *Node {
+	var trie = &Node{}
+	for _, word := range words {
+		AddTrie(trie, []rune(word), true, false)
+	}
+	return trie
+}
+
+func buildCompactTrie(tb testing.TB, trie *Node) *CompactTrie {
+	var data, err = EncodeCompactTrie(trie)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	compact, err := NewCompactTrie(data)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return compact
+}
+
+func TestCompactTrieMatchesNodeTrie(t *testing.T) {
+	var words = []string{"việt", "Việt", "nam", "đắk", "lắk", "krông", "hoà", "hòa", "giặt", "gì"}
+	var trie = buildNodeTrie(words)
+	for _, word := range DefaultSpellingRules.GenerateWords()[:200] {
+		AddTrie(trie, []rune(word), false, false)
+	}
+	var compact = buildCompactTrie(t, trie)
+	var queries = []string{"", "v", "vi", "viê", "viet", "việ", "việt", "VIỆT", "Việt", "dak", "đăk", "đắk", "krô", "hoa", "hòa", "giat", "x", "việtx"}
+	for _, query := range queries {
+		var chars = []rune(query)
+		for _, dictionary := range []bool{false, true} {
+			if r1, r2 := TestString(trie, chars, dictionary), compact.TestString(chars, dictionary); r1 != r2 {
+				t.Errorf("TestString [%s] dictionary=%v, expected [%d], got [%d]", query, dictionary, r1, r2)
+			}
+		}
+		var node = FindNode(trie, chars)
+		var compactNode, found = compact.FindNode(chars)
+		if (node != nil) != found {
+			t.Errorf("FindNode [%s], expected found=%v", query, node != nil)
+			continue
+		}
+		if node != nil && (node.Full != compactNode.Full() || node.Dictionary != compactNode.Dictionary() || len(node.Children) != compactNode.Len()) {
+			t.Errorf("FindNode [%s], the compact node differs", query)
+		}
+		var words1, words2 = FindWords(trie, query), compact.FindWords(query)
+		sort.Strings(words1)
+		sort.Strings(words2)
+		if !reflect.DeepEqual(words1, words2) {
+			t.Errorf("FindWords [%s], expected %q, got %q", query, words1, words2)
+		}
+	}
+}
+
+func TestCompactTrieFormat(t *testing.T) {
+	var data, err = EncodeCompactTrie(buildNodeTrie([]string{"việt"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewCompactTrie(data[:len(data)-1]); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a truncated trie, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	var newer = append([]byte{}, data...)
+	newer[4] = CompactTrieVersion + 1
+	if _, err = NewCompactTrie(newer); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a newer trie, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	var nodeCount = binary.LittleEndian.Uint32(data[8:])
+	var badEdges = append([]byte{}, data...)
+	binary.LittleEndian.PutUint16(badEdges[compactTrieHeaderSize+4:], 0xffff)
+	if _, err = NewCompactTrie(badEdges); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a trie with too many edges, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	var badChild = append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(badChild[compactTrieHeaderSize+int(nodeCount)*compactNodeSize+4:], nodeCount)
+	if _, err = NewCompactTrie(badChild); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a trie with a missing child, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	// 2^29 more nodes take 2^32 more bytes, the same size in 32 bits
+	var wrapped = append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(wrapped[8:], nodeCount+1<<29)
+	if _, err = NewCompactTrie(wrapped); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a trie whose size overflows 32 bits, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	var farEdges = append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(farEdges[compactTrieHeaderSize:], 0xffffffff)
+	if _, err = NewCompactTrie(farEdges); !errors.Is(err, ErrCompactTrieFormat) {
+		t.Errorf("Read a trie with edges past 2^32, expected [%v], got [%v]", ErrCompactTrieFormat, err)
+	}
+	var fileName = filepath.Join(t.TempDir(), "words.trie")
+	if err = ioutil.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	trie, err := OpenCompactTrie(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := trie.TestString([]rune("việt"), true); r != FindResultMatchFull {
+		t.Errorf("TestString [việt] of the opened trie, expected [%d], got [%d]", FindResultMatchFull, r)
+	}
+	if r := trie.TestString([]rune("vi"), false); r != FindResultMatchPrefix {
+		t.Errorf("TestString [vi] of the opened trie, expected [%d], got [%d]", FindResultMatchPrefix, r)
+	}
+	if err = trie.Close(); err != nil {
+		t.Errorf("Close the trie: %v", err)
+	}
+}
+
+func TestSpellingDictionaryTrie(t *testing.T) {
+	var dictionary = map[string]bool{"đắk": true, "lắk": true, "krông": true, "pơng": true}
+	var merged = buildSpellingTrie(DefaultSpellingRules, nil)
+	for word := range dictionary {
+		AddTrie(merged, []rune(word), true, false)
+	}
+	var compact = buildCompactTrie(t, buildNodeTrie([]string{"đắk", "lắk", "krông", "pơng"}))
+	SetSpellingDictionaryTrie(compact)
+	defer SetSpellingDictionaryTrie(nil)
+	for _, query := range []string{"đắk", "đăk", "dak", "Đắk", "krô", "krông", "pơng", "pong", "tiếng", "tieng", "xyz"} {
+		for _, dictionary := range []bool{false, true} {
+			if r1, r2 := TestString(merged, []rune(query), dictionary), testSpelling([]rune(query), dictionary); r1 != r2 {
+				t.Errorf("Test spelling of [%s] dictionary=%v, expected [%d], got [%d]", query, dictionary, r1, r2)
+			}
+		}
+	}
+	if !CanExtendSpelling("kr") {
+		t.Errorf("Test extending kr, expected [true], got [false]")
+	}
+}
+
+// heapSize returns the heap memory which is kept by the result of build.
+func heapSize(build func() interface{}) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var result = build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(result)
+	return after.HeapAlloc - before.HeapAlloc
+}
+
+func BenchmarkNodeTrieHeap(b *testing.B) {
+	var words = generateToneVariantWords()
+	for i := 0; i < b.N; i++ {
+		b.ReportMetric(float64(heapSize(func() interface{} {
+			return buildNodeTrie(words)
+		})), "heap-bytes")
+	}
+}
+
+func BenchmarkCompactTrieHeap(b *testing.B) {
+	var data, _ = EncodeCompactTrie(buildNodeTrie(generateToneVariantWords()))
+	for i := 0; i < b.N; i++ {
+		// a mapped file is not part of the heap, copying the data into it is
+		// the worst case of a platform without mmap
+		b.ReportMetric(float64(heapSize(func() interface{} {
+			var trie, _ = NewCompactTrie(append([]byte{}, data...))
+			return trie
+		})), "heap-bytes")
+	}
+}
+
+func BenchmarkNodeTrieTestString(b *testing.B) {
+	var words = generateToneVariantWords()
+	var trie = buildNodeTrie(words)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TestString(trie, []rune(words[i%len(words)]), true)
+	}
+}
+
+func BenchmarkCompactTrieTestString(b *testing.B) {
+	var words = generateToneVariantWords()
+	var trie = buildCompactTrie(b, buildNodeTrie(words))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trie.TestString([]rune(words[i%len(words)]), true)
+	}
+}
+
+// the startup benchmarks measure how long the spelling takes to be ready from
+// the files: reading the word list and building the trie against mapping the
+// compiled trie, which is validated once
+func BenchmarkNodeTrieStartup(b *testing.B) {
+	var fileName = filepath.Join(b.TempDir(), "words.dict")
+	if err := ioutil.WriteFile(fileName, []byte(strings.Join(generateToneVariantWords(), "\n")), 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var data, err = ioutil.ReadFile(fileName)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var trie = buildNodeTrie(strings.Split(string(data), "\n"))
+		TestString(trie, []rune("việt"), true)
+	}
+}
+
+func BenchmarkCompactTrieStartup(b *testing.B) {
+	var data, _ = EncodeCompactTrie(buildNodeTrie(generateToneVariantWords()))
+	var fileName = filepath.Join(b.TempDir(), "words.trie")
+	if err := ioutil.WriteFile(fileName, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var trie, err = OpenCompactTrie(fileName)
+		if err != nil {
+			b.Fatal(err)
+		}
+		trie.TestString([]rune("việt"), true)
+		trie.Close()
+	}
+}
@@ -0,0 +1,344 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Dictionary looks lower-case words and phrases, e.g. "việt", "việt nam", up in
+// compact spelling tries, to list completions and to restore the accents of
+// plain ASCII text. The system dictionary is searched in the trie the spelling
+// is checked against, no other copy of its words is kept in memory.
+type Dictionary struct {
+	tries []*CompactTrie
+	// how many times the user picked a word, see Learn
+	counts map[string]int
+	model  *BigramModel
+}
+
+// NewDictionary makes a dictionary of the words and of the given tries, e.g.
+// the system dictionary mapped by OpenCompactTrie.
+func NewDictionary(words []string, tries ...*CompactTrie) *Dictionary {
+	var d = &Dictionary{counts: map[string]int{}, model: NewBigramModel()}
+	for _, trie := range tries {
+		if trie != nil {
+			d.tries = append(d.tries, trie)
+		}
+	}
+	var trie = &Node{}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			AddTrie(trie, []rune(word), true, false)
+		}
+	}
+	if len(trie.Children) > 0 {
+		// a trie built here is always well formed
+		var data, _ = EncodeCompactTrie(trie)
+		var compactTrie, _ = NewCompactTrie(data)
+		d.tries = append(d.tries, compactTrie)
+	}
+	return d
+}
+
+// Len returns the number of words, a word found in several tries is counted
+// once per trie.
+func (d *Dictionary) Len() int {
+	var n = 0
+	for _, trie := range d.tries {
+		for i := 0; i < trie.Len(); i++ {
+			if (CompactNode{trie, uint32(i)}).Full() {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func (d *Dictionary) Contains(word string) bool {
+	var chars = []rune(strings.ToLower(word))
+	for _, trie := range d.tries {
+		if node, found := trie.FindNode(chars); found && node.Full() {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete returns at most limit words which start with the prefix, shorter
+// words first. The prefix itself is not a completion.
+func (d *Dictionary) Complete(prefix string, limit int) []string {
+	prefix = strings.ToLower(prefix)
+	if prefix == "" {
+		return nil
+	}
+	var words []string
+	for _, trie := range d.tries {
+		for _, word := range trie.FindWords(prefix) {
+			if word != prefix {
+				words = append(words, word)
+			}
+		}
+	}
+	words = sortUnique(words)
+	sort.SliceStable(words, func(i, j int) bool {
+		return len([]rune(words[i])) < len([]rune(words[j]))
+	})
+	if limit > 0 && len(words) > limit {
+		words = words[:limit]
+	}
+	return words
+}
+
+// SetBigramModel ranks the restorations by the word pairs of the model.
+func (d *Dictionary) SetBigramModel(model *BigramModel) {
+	d.model = model
+}
+
+// Learn makes the word rank higher in the next restorations.
+func (d *Dictionary) Learn(word string) {
+	d.counts[strings.ToLower(word)]++
+}
+
+// LearnFrom adds the learned counts of another dictionary, e.g. the one this
+// dictionary replaces.
+func (d *Dictionary) LearnFrom(other *Dictionary) {
+	for word, count := range other.counts {
+		d.counts[word] += count
+	}
+}
+
+// HasAccentlessPrefix tells whether some word or phrase starts with the given
+// text once their accents are removed, e.g. "viet " for "việt nam".
+func (d *Dictionary) HasAccentlessPrefix(prefix string) bool {
+	var key = []rune(RemoveAccents(prefix))
+	for _, trie := range d.tries {
+		var found = walkAccentless(trie.root(), key, nil, func([]rune, CompactNode) bool {
+			return true
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAccentedWords caps the words a restoration looks up for a key, a short
+// key like "a" reads as dozens of them and the best ones rank the same.
+const maxAccentedWords = 64
+
+// Restore returns at most limit words or phrases which read as the text once
+// their accents are removed, e.g. "viet nam" -> "việt nam". The candidates
+// which follow the previous word come first, then the ones the user picked the
+// most, then the most frequent ones of the bigram model. A phrase which is not
+// in the dictionary is restored word by word.
+func (d *Dictionary) Restore(text, previous string, limit int) []string {
+	var key = RemoveAccents(strings.Join(strings.Fields(text), " "))
+	if key == "" {
+		return nil
+	}
+	var candidates = d.findAccented(key)
+	d.rank(candidates, previous)
+	if words := strings.Fields(key); len(words) > 1 {
+		var restored []string
+		for _, word := range words {
+			var best, ok = d.best(d.findAccented(word), previous)
+			if !ok {
+				restored = nil
+				break
+			}
+			restored = append(restored, best)
+			previous = best
+		}
+		if phrase := strings.Join(restored, " "); phrase != "" && !containsString(candidates, phrase) {
+			candidates = append(candidates, phrase)
+		}
+	}
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// findAccented lists the words of every trie which read as the accentless key,
+// at most maxAccentedWords of each trie.
+func (d *Dictionary) findAccented(key string) []string {
+	var candidates []string
+	for _, trie := range d.tries {
+		candidates = append(candidates, findAccented(trie, []rune(key), maxAccentedWords)...)
+	}
+	return sortUnique(candidates)
+}
+
+// walkAccentless visits the nodes whose path reads as the accentless key, it
+// follows every edge whose char reads as the next char of the key and stops
+// as soon as visit returns true.
+func walkAccentless(node CompactNode, key []rune, prefix []rune, visit func(path []rune, node CompactNode) bool) bool {
+	if len(key) == 0 {
+		return visit(prefix, node)
+	}
+	for i := 0; i < node.Len(); i++ {
+		if chr, child := node.edge(i); removeAccent(chr) == key[0] {
+			if walkAccentless(child, key[1:], append(prefix, chr), visit) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findAccented lists at most limit words which read as the accentless key.
+// Only the words themselves are full nodes, the paths which take a down-level
+// edge of the trie lead to none.
+func findAccented(trie *CompactTrie, key []rune, limit int) []string {
+	var words []string
+	walkAccentless(trie.root(), key, nil, func(path []rune, node CompactNode) bool {
+		if node.Full() {
+			words = append(words, string(path))
+		}
+		return len(words) >= limit
+	})
+	return words
+}
+
+type restoreScore struct {
+	context, learned, frequency int
+}
+
+func (a restoreScore) less(b restoreScore) bool {
+	if a.context != b.context {
+		return a.context < b.context
+	}
+	if a.learned != b.learned {
+		return a.learned < b.learned
+	}
+	return a.frequency < b.frequency
+}
+
+func (d *Dictionary) score(candidate, previous string) restoreScore {
+	var s = restoreScore{learned: d.counts[candidate], frequency: d.model.Frequency(candidate)}
+	if previous != "" {
+		s.context = d.model.Count(previous, candidate)
+		if d.Contains(previous + " " + candidate) {
+			s.context += 1 << 16
+		}
+	}
+	return s
+}
+
+func (d *Dictionary) rank(candidates []string, previous string) {
+	previous = strings.ToLower(previous)
+	var scores = make(map[string]restoreScore, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate] = d.score(candidate, previous)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[j]].less(scores[candidates[i]])
+	})
+}
+
+// best is the first candidate rank would give, without sorting them all.
+func (d *Dictionary) best(candidates []string, previous string) (string, bool) {
+	if len(candidates) == 0 {
+		return "", false
+	}
+	previous = strings.ToLower(previous)
+	var best, bestScore = candidates[0], d.score(candidates[0], previous)
+	for _, candidate := range candidates[1:] {
+		if s := d.score(candidate, previous); bestScore.less(s) {
+			best, bestScore = candidate, s
+		}
+	}
+	return best, true
+}
+
+// The learned counts are stored as plain text, one "word<TAB>count" per line.
+
+// ReadCounts adds the counts read from r to the learned ones.
+func (d *Dictionary) ReadCounts(r io.Reader) error {
+	var scanner = bufio.NewScanner(r)
+	var lineNumber = 0
+	for scanner.Scan() {
+		lineNumber++
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var i = strings.LastIndexByte(line, '\t')
+		if i <= 0 {
+			return fmt.Errorf("line %d: malformed count %q", lineNumber, line)
+		}
+		var count, err = strconv.Atoi(line[i+1:])
+		if err != nil || count <= 0 {
+			return fmt.Errorf("line %d: malformed count %q", lineNumber, line)
+		}
+		d.counts[strings.ToLower(line[:i])] += count
+	}
+	return scanner.Err()
+}
+
+// WriteCounts writes the learned counts in the format of ReadCounts, the words
+// are sorted.
+func (d *Dictionary) WriteCounts(w io.Writer) (int64, error) {
+	var words = make([]string, 0, len(d.counts))
+	for word := range d.counts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	var bw = bufio.NewWriter(w)
+	var n int64
+	for _, word := range words {
+		written, err := bw.WriteString(word + "\t" + strconv.Itoa(d.counts[word]) + "\n")
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+func sortUnique(words []string) []string {
+	sort.Strings(words)
+	var unique = words[:0]
+	for i, word := range words {
+		if i == 0 || word != words[i-1] {
+			unique = append(unique, word)
+		}
+	}
+	return unique
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeAccent(chr rune) rune {
+	return RemoveMarkFromChar(AddToneToChar(unicode.ToLower(chr), 0))
+}
+
+// RemoveAccents strips the tones and the marks of a text, e.g. "Đường" -> "duong".
+// The result is in lower case.
+func RemoveAccents(text string) string {
+	var chars = []rune(text)
+	for i, chr := range chars {
+		chars[i] = removeAccent(chr)
+	}
+	return string(chars)
+}
@@ -0,0 +1,139 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDictionaryComplete(t *testing.T) {
+	var d = NewDictionary([]string{"việt", "Việt Nam", "việc", "viết", "tiếng việt", "việt", ""})
+	if d.Len() != 5 {
+		t.Errorf("Dictionary length, expected [5], got [%d]", d.Len())
+	}
+	if words := d.Complete("việ", 0); !reflect.DeepEqual(words, []string{"việc", "việt", "việt nam"}) {
+		t.Errorf("Complete [việ], got %q", words)
+	}
+	if words := d.Complete("Việt", 0); !reflect.DeepEqual(words, []string{"việt nam"}) {
+		t.Errorf("Complete [Việt], got %q", words)
+	}
+	if words := d.Complete("vi", 2); len(words) != 2 {
+		t.Errorf("Complete [vi] with a limit, got %q", words)
+	}
+	if words := d.Complete("x", 0); len(words) != 0 {
+		t.Errorf("Complete [x], got %q", words)
+	}
+	if !d.Contains("VIỆT NAM") || d.Contains("việt na") {
+		t.Errorf("Dictionary lookup, expected to find only whole words")
+	}
+}
+
+func TestDictionaryRestore(t *testing.T) {
+	var d = NewDictionary([]string{"việt", "viết", "việt nam", "tiếng việt", "người việt", "nam", "năm", "nấm", "năm mới", "năm nay", "là"})
+	var model, err = LoadBigramModel(strings.NewReader("tiếng\tviệt 5\nngười\tviệt 3\tviết 1\nmột\tnăm 4\nviệt\tnam 2\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetBigramModel(model)
+	if words := d.Restore("viet", "", 0); !reflect.DeepEqual(words, []string{"việt", "viết"}) {
+		t.Errorf("Restore [viet], got %q", words)
+	}
+	if words := d.Restore("Viet  Nam", "", 0); !reflect.DeepEqual(words, []string{"việt nam"}) {
+		t.Errorf("Restore [Viet  Nam], got %q", words)
+	}
+	if words := d.Restore("nam", "việt", 0); len(words) != 3 || words[0] != "nam" {
+		t.Errorf("Restore [nam] after [việt], got %q", words)
+	}
+	if words := d.Restore("nam", "", 0); !reflect.DeepEqual(words, []string{"năm", "nam", "nấm"}) {
+		t.Errorf("Restore [nam], expected the most frequent word first, got %q", words)
+	}
+	d.Learn("nấm")
+	d.Learn("nấm")
+	d.Learn("nấm")
+	if words := d.Restore("nam", "", 1); !reflect.DeepEqual(words, []string{"nấm"}) {
+		t.Errorf("Restore [nam] after learning [nấm], got %q", words)
+	}
+	if words := d.Restore("viet la", "", 0); !reflect.DeepEqual(words, []string{"việt là"}) {
+		t.Errorf("Restore [viet la], got %q", words)
+	}
+	if words := d.Restore("xyz", "", 0); len(words) != 0 {
+		t.Errorf("Restore [xyz], got %q", words)
+	}
+	var many []string
+	for _, a := range "aàáảãạăằắẳẵặâầấẩẫậ" {
+		for _, o := range "oòóỏõọ" {
+			many = append(many, string([]rune{a, o}))
+		}
+	}
+	if words := NewDictionary(many).Restore("ao", "", 0); len(words) != maxAccentedWords {
+		t.Errorf("Restore [ao], expected %d of the %d words, got %d", maxAccentedWords, len(many), len(words))
+	}
+	if !d.HasAccentlessPrefix("viet ") || d.HasAccentlessPrefix("la ") {
+		t.Errorf("Accentless prefix lookup, expected only [viet ] to be found")
+	}
+}
+
+func TestDictionaryTries(t *testing.T) {
+	var trie = &Node{}
+	for _, word := range []string{"việt", "viết", "nam"} {
+		AddTrie(trie, []rune(word), true, false)
+	}
+	var data, _ = EncodeCompactTrie(trie)
+	var compactTrie, err = NewCompactTrie(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d = NewDictionary([]string{"việt nam"}, compactTrie, nil)
+	if !d.Contains("viết") || !d.Contains("việt nam") || d.Len() != 4 {
+		t.Errorf("Dictionary of a trie and a list, expected 4 words, got [%d]", d.Len())
+	}
+	if words := d.Complete("việ", 0); !reflect.DeepEqual(words, []string{"việt", "việt nam"}) {
+		t.Errorf("Complete [việ] in both tries, got %q", words)
+	}
+	if words := d.Restore("viet", "", 0); !reflect.DeepEqual(words, []string{"viết", "việt"}) {
+		t.Errorf("Restore [viet] from a compact trie, got %q", words)
+	}
+	if !d.HasAccentlessPrefix("vie") || !d.HasAccentlessPrefix("viet n") || d.HasAccentlessPrefix("vu") {
+		t.Errorf("Accentless prefix lookup in both tries")
+	}
+}
+
+func TestDictionaryCounts(t *testing.T) {
+	var d = NewDictionary([]string{"nam", "năm", "nấm"})
+	if err := d.ReadCounts(strings.NewReader("# learned\nnấm\t3\nnăm\t1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if words := d.Restore("nam", "", 0); !reflect.DeepEqual(words, []string{"nấm", "năm", "nam"}) {
+		t.Errorf("Restore [nam] with the read counts, got %q", words)
+	}
+	d.Learn("Nam")
+	var buf bytes.Buffer
+	if _, err := d.WriteCounts(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "nam\t1\nnăm\t1\nnấm\t3\n"; buf.String() != expected {
+		t.Errorf("Write the counts, expected [%q], got [%q]", expected, buf.String())
+	}
+	var other = NewDictionary([]string{"nam", "năm", "nấm"})
+	other.LearnFrom(d)
+	if words := other.Restore("nam", "", 0); !reflect.DeepEqual(words, []string{"nấm", "nam", "năm"}) {
+		t.Errorf("Restore [nam] with the counts of another dictionary, got %q", words)
+	}
+	if err := d.ReadCounts(strings.NewReader("nam 1\n")); err == nil {
+		t.Errorf("Read a malformed count, expected an error")
+	}
+}
+
+func TestRemoveAccents(t *testing.T) {
+	if s := RemoveAccents("Đường Việt Nam"); s != "duong viet nam" {
+		t.Errorf("RemoveAccents, got [%s]", s)
+	}
+}
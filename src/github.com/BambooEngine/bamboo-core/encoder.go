@@ -7,27 +7,190 @@
  */
 package bamboo
 
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
 const UNICODE = "Unicode"
 
 func Encode(charsetName string, input string) string {
 	if charsetName == UNICODE {
 		return input
 	}
-	var output string
-	if charset, found := charsetDefinitions[charsetName]; found {
-		for _, chr := range []rune(input) {
-			if out, found := charset[chr]; found {
-				output = output + out
-			} else {
-				output = output + string(chr)
+	charset, found := charsetDefinitions[charsetName]
+	if !found {
+		return input
+	}
+	var output strings.Builder
+	for _, chr := range input {
+		if out, found := charset[chr]; found {
+			output.WriteString(out)
+		} else {
+			output.WriteRune(chr)
+		}
+	}
+	return output.String()
+}
+
+// Decode converts a text in the given charset back to Unicode. A character may
+// be encoded by several runes (VNI Windows, VIQR...), the longest sequence
+// found in the charset wins.
+func Decode(charsetName string, input string) string {
+	if charsetName == UNICODE {
+		return input
+	}
+	decoder := getCharsetDecoder(charsetName)
+	if decoder == nil {
+		return input
+	}
+	var output strings.Builder
+	var chars = []rune(input)
+	for i := 0; i < len(chars); {
+		var n = decoder.maxLen
+		if n > len(chars)-i {
+			n = len(chars) - i
+		}
+		for ; n > 0; n-- {
+			if chr, found := decoder.table[string(chars[i:i+n])]; found {
+				output.WriteRune(chr)
+				break
 			}
 		}
-	} else {
-		output = input
+		if n == 0 {
+			output.WriteRune(chars[i])
+			n = 1
+		}
+		i += n
+	}
+	return output.String()
+}
+
+type charsetDecoder struct {
+	table  map[string]rune
+	maxLen int
+}
+
+var charsetDecoders = struct {
+	sync.Mutex
+	m map[string]*charsetDecoder
+}{m: map[string]*charsetDecoder{}}
+
+func getCharsetDecoder(charsetName string) *charsetDecoder {
+	charsetDecoders.Lock()
+	defer charsetDecoders.Unlock()
+	if decoder, found := charsetDecoders.m[charsetName]; found {
+		return decoder
+	}
+	charset, found := charsetDefinitions[charsetName]
+	if !found {
+		return nil
+	}
+	var decoder = &charsetDecoder{table: make(map[string]rune, len(charset))}
+	for chr, seq := range charset {
+		// some charsets (TCVN3, VISCII...) share a code between the upper and the
+		// lower case of a letter, prefer the lower case one
+		if old, found := decoder.table[seq]; found && !isPreferredDecoding(chr, old) {
+			continue
+		}
+		decoder.table[seq] = chr
+		if n := len([]rune(seq)); n > decoder.maxLen {
+			decoder.maxLen = n
+		}
+	}
+	charsetDecoders.m[charsetName] = decoder
+	return decoder
+}
+
+func isPreferredDecoding(chr, old rune) bool {
+	if unicode.IsLower(chr) != unicode.IsLower(old) {
+		return unicode.IsLower(chr)
+	}
+	return chr < old
+}
+
+// The 8-bit charsets are spelled in charsetDefinitions with the Windows-1252
+// rune of each byte, EncodeBytes and DecodeBytes map them back to raw bytes.
+var byteCharsets = map[string]bool{
+	"TCVN3 (ABC)":           true,
+	"VNI Windows":           true,
+	"Windows 1258 codepage": true,
+	"VISCII":                true,
+	"VPS":                   true,
+	"BKHCM 1":               true,
+	"BKHCM 2":               true,
+	"Vietware X":            true,
+	"Vietware Full":         true,
+}
+
+// the bytes 0x80-0x9F of Windows-1252, the undefined ones keep their C1 rune
+var cp1252Runes = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// IsByteCharset reports whether a charset encodes each character in one or
+// more single bytes rather than in Unicode text.
+func IsByteCharset(charsetName string) bool {
+	return byteCharsets[charsetName]
+}
+
+func byteToRune(charsetName string, b byte) rune {
+	if b == 0xD0 && charsetName == "Windows 1258 codepage" {
+		return 'Đ'
+	}
+	if b >= 0x80 && b < 0xA0 {
+		return cp1252Runes[b-0x80]
+	}
+	return rune(b)
+}
+
+func runeToByte(charsetName string, chr rune) (byte, bool) {
+	if chr < 0x80 || chr >= 0xA0 && chr <= 0xFF {
+		return byte(chr), chr != 0xD0 || charsetName != "Windows 1258 codepage"
+	}
+	if chr == 'Đ' && charsetName == "Windows 1258 codepage" {
+		return 0xD0, true
+	}
+	for i, r := range cp1252Runes {
+		if r == chr {
+			return byte(0x80 + i), true
+		}
+	}
+	return 0, false
+}
+
+// EncodeBytes is Encode for files and streams, the 8-bit charsets are written
+// byte per byte instead of UTF-8, a character they can't encode becomes '?'.
+func EncodeBytes(charsetName string, input string) []byte {
+	var text = Encode(charsetName, input)
+	if !byteCharsets[charsetName] {
+		return []byte(text)
+	}
+	var output = make([]byte, 0, len(text))
+	for _, chr := range text {
+		if b, ok := runeToByte(charsetName, chr); ok {
+			output = append(output, b)
+		} else {
+			output = append(output, '?')
+		}
 	}
 	return output
 }
 
+// DecodeBytes is the reverse of EncodeBytes.
+func DecodeBytes(charsetName string, input []byte) string {
+	if !byteCharsets[charsetName] {
+		return Decode(charsetName, string(input))
+	}
+	var text = make([]rune, len(input))
+	for i, b := range input {
+		text[i] = byteToRune(charsetName, b)
+	}
+	return Decode(charsetName, string(text))
+}
+
 func GetCharsetNames() []string {
 	var names []string
 	names = append(names, UNICODE)
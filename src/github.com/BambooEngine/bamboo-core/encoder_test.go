@@ -0,0 +1,88 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleText = "Tiếng Việt là ngôn ngữ của người Việt, ngữ điệu rất êm đềm và dịu dàng. 123"
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, cs := range GetCharsetNames() {
+		var encoded = Encode(cs, sampleText)
+		if cs != UNICODE && cs != "Unicode tổ hợp" && encoded == sampleText {
+			t.Errorf("Encode to %s, expected a different text", cs)
+		}
+		if decoded := Decode(cs, encoded); decoded != sampleText {
+			t.Errorf("Round trip %s, expected [%s], got [%s]", cs, sampleText, decoded)
+		}
+	}
+}
+
+func TestEncodeDecodeAllCharacters(t *testing.T) {
+	for _, cs := range GetCharsetNames() {
+		var charset = charsetDefinitions[cs]
+		var codes = map[string]int{}
+		for _, seq := range charset {
+			codes[seq]++
+		}
+		for chr, seq := range charset {
+			// the upper and the lower case share this code
+			if codes[seq] > 1 {
+				continue
+			}
+			var text = "x" + string(chr) + "y"
+			if decoded := Decode(cs, Encode(cs, text)); decoded != text {
+				t.Errorf("Round trip %c in %s, expected [%s], got [%s]", chr, cs, text, decoded)
+			}
+		}
+	}
+}
+
+func TestDecodeLongestMatch(t *testing.T) {
+	if out := Decode("VIQR", "Vie^.t Nam"); out != "Việt Nam" {
+		t.Errorf("Decode VIQR, expected [Việt Nam], got [%s]", out)
+	}
+	if out := Decode("VNI Windows", Encode("VNI Windows", "ữ")); out != "ữ" {
+		t.Errorf("Decode VNI Windows, expected [ữ], got [%s]", out)
+	}
+	if out := Decode("TCVN3 (ABC)", Encode("TCVN3 (ABC)", "Ã")); out != "ã" {
+		t.Errorf("Decode a shared TCVN3 code, expected [ã], got [%s]", out)
+	}
+	if out := Decode("Unknown", "abc"); out != "abc" {
+		t.Errorf("Decode an unknown charset, expected [abc], got [%s]", out)
+	}
+}
+
+func TestEncodeDecodeBytes(t *testing.T) {
+	if out := EncodeBytes("TCVN3 (ABC)", "Việt"); string(out) != "Vi\xd6t" {
+		t.Errorf("Encode bytes to TCVN3, expected [% x], got [% x]", "Vi\xd6t", out)
+	}
+	if out := DecodeBytes("TCVN3 (ABC)", []byte("Vi\xd6t")); out != "Việt" {
+		t.Errorf("Decode bytes from TCVN3, expected [Việt], got [%s]", out)
+	}
+	if out := EncodeBytes("Windows 1258 codepage", "Đ"); string(out) != "\xd0" {
+		t.Errorf("Encode bytes to Windows 1258, expected [d0], got [% x]", out)
+	}
+	if out := EncodeBytes("VIQR", "Việt"); string(out) != "Vie^.t" {
+		t.Errorf("Encode bytes to VIQR, expected [Vie^.t], got [%s]", out)
+	}
+	for cs := range byteCharsets {
+		for chr, seq := range charsetDefinitions[cs] {
+			var encoded = EncodeBytes(cs, string(chr))
+			if strings.Contains(string(encoded), "?") || len(encoded) != len([]rune(seq)) {
+				t.Errorf("Encode bytes %c in %s, expected one byte per rune of [%s], got [% x]", chr, cs, seq, encoded)
+			}
+		}
+		if decoded := DecodeBytes(cs, EncodeBytes(cs, sampleText)); decoded != sampleText {
+			t.Errorf("Round trip bytes %s, expected [%s], got [%s]", cs, sampleText, decoded)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// maxCachedInputMethods is how many compiled input methods are kept, enough
+// for the built-in ones in use and the last edits of a custom one.
+const maxCachedInputMethods = 8
+
+type cachedInputMethod struct {
+	hash string
+	im   InputMethod
+}
+
+// compiled input methods, the most recently used last
+var inputMethodCache struct {
+	sync.Mutex
+	entries []cachedInputMethod
+}
+
+func hashInputMethodDefinition(imName string, imDefinition InputMethodDefinition) string {
+	var keys []string
+	for key := range imDefinition {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var h = sha1.New()
+	h.Write([]byte(imName))
+	for _, key := range keys {
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(imDefinition[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LookupInputMethod returns the compiled input method of the definition, it is
+// only parsed again once the definition has not been used among the last
+// maxCachedInputMethods ones. The returned InputMethod is shared, callers must
+// not modify it.
+func LookupInputMethod(imName string, imDefinition InputMethodDefinition) InputMethod {
+	var hash = hashInputMethodDefinition(imName, imDefinition)
+	if im, found := lookupCachedInputMethod(hash); found {
+		return im
+	}
+	var im = parseInputMethods(map[string]InputMethodDefinition{imName: imDefinition})[imName]
+	inputMethodCache.Lock()
+	defer inputMethodCache.Unlock()
+	if len(inputMethodCache.entries) >= maxCachedInputMethods {
+		inputMethodCache.entries = append(inputMethodCache.entries[:0], inputMethodCache.entries[1:]...)
+	}
+	inputMethodCache.entries = append(inputMethodCache.entries, cachedInputMethod{hash, im})
+	return im
+}
+
+// lookupCachedInputMethod finds the compiled input method and moves it to the
+// most recently used end.
+func lookupCachedInputMethod(hash string) (InputMethod, bool) {
+	inputMethodCache.Lock()
+	defer inputMethodCache.Unlock()
+	var entries = inputMethodCache.entries
+	for i, entry := range entries {
+		if entry.hash == hash {
+			copy(entries[i:], entries[i+1:])
+			entries[len(entries)-1] = entry
+			return entry.im, true
+		}
+	}
+	return InputMethod{}, false
+}
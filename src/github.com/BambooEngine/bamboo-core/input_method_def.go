@@ -9,7 +9,8 @@ package bamboo
 
 type InputMethodDefinition map[string]string
 
-// todo: move to input_method.json
+// InputMethodDefinitions is the built-in copy of input_method.json, it is used
+// whenever the definition files can not be found.
 var InputMethodDefinitions = map[string]InputMethodDefinition{
 	"Telex": {
 		"z": "XoaDauThanh",
@@ -171,4 +172,22 @@ var InputMethodDefinitions = map[string]InputMethodDefinition{
 		"[": "__ươ",
 		"{": "_ƯƠ",
 	},
+	// the marks only go on the letter typed right before the key, a lone w
+	// stays a w
+	"Simple Telex": {
+		"z":   "XoaDauThanh",
+		"s":   "DauSac",
+		"f":   "DauHuyen",
+		"r":   "DauHoi",
+		"x":   "DauNga",
+		"j":   "DauNang",
+		"aa":  "A_Â",
+		"aw":  "A_Ă",
+		"ee":  "E_Ê",
+		"oo":  "O_Ô",
+		"ow":  "O_Ơ",
+		"uw":  "U_Ư",
+		"uow": "UO_ƯƠ",
+		"dd":  "D_Đ",
+	},
 }
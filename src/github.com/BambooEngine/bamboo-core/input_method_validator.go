@@ -0,0 +1,126 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownInputMethod = errors.New("unknown input method")
+	ErrUnknownTone        = errors.New("unknown tone name")
+	ErrMalformedDsl       = errors.New("malformed rule")
+	ErrLengthMismatch     = errors.New("effective characters and results differ in length")
+	ErrDuplicateKey       = errors.New("key is bound more than once")
+	ErrEmptyKey           = errors.New("key is empty")
+	ErrUnknownModifier    = errors.New("unknown modifier")
+)
+
+// InputMethodError describes a problem found in a line of an input method
+// definition. Err is one of the ErrXxx values above.
+type InputMethodError struct {
+	InputMethod string
+	Key         string
+	Line        string
+	Err         error
+}
+
+func (e *InputMethodError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %v", e.InputMethod, e.Err)
+	}
+	return fmt.Sprintf("%s: %q: %q: %v", e.InputMethod, e.Key, e.Line, e.Err)
+}
+
+func (e *InputMethodError) Unwrap() error {
+	return e.Err
+}
+
+var regToneName = regexp.MustCompile(`^[a-zA-Z]+$`)
+var regFullDsl = regexp.MustCompile(`^` + regDsl.String() + `$`)
+var regFullDslAppending = regexp.MustCompile(`^` + regDslAppending.String() + `$`)
+
+// ValidateInputMethod works as ParseInputMethod but reports every key and line
+// which ParseInputMethod would silently skip or misread. The returned input
+// method is built from the valid lines only.
+func ValidateInputMethod(imDef map[string]InputMethodDefinition, imName string) (InputMethod, []error) {
+	imDefinition, found := imDef[imName]
+	if !found {
+		return InputMethod{}, []error{&InputMethodError{InputMethod: imName, Err: ErrUnknownInputMethod}}
+	}
+	var errs []error
+	var validDefinition = InputMethodDefinition{}
+	var boundKeys = map[string]string{}
+	var keyStrs []string
+	for keyStr := range imDefinition {
+		keyStrs = append(keyStrs, keyStr)
+	}
+	sort.Strings(keyStrs)
+	for _, keyStr := range keyStrs {
+		var line = imDefinition[keyStr]
+		var boundKey, err = validateKey(keyStr, boundKeys)
+		if err == nil {
+			err = validateLine(line)
+		}
+		if err != nil {
+			errs = append(errs, &InputMethodError{InputMethod: imName, Key: keyStr, Line: line, Err: err})
+			continue
+		}
+		boundKeys[boundKey] = keyStr
+		validDefinition[keyStr] = line
+	}
+	return LookupInputMethod(imName, validDefinition), errs
+}
+
+// validateKey returns the key as it is matched while typing, "S" and "s" or
+// "AltGr+D" and "AltGr+d" are the same key.
+func validateKey(keyStr string, boundKeys map[string]string) (string, error) {
+	var keyDef, err = ParseKeyDefinition(keyStr)
+	if err != nil {
+		return "", err
+	}
+	var boundKey = fmt.Sprintf("%d+%s", keyDef.Modifiers, strings.ToLower(string(append(keyDef.Prefix, keyDef.Key))))
+	if _, found := boundKeys[boundKey]; found {
+		return "", ErrDuplicateKey
+	}
+	return boundKey, nil
+}
+
+func validateLine(line string) error {
+	if _, found := tones[line]; found {
+		return nil
+	}
+	if regToneName.MatchString(line) {
+		return ErrUnknownTone
+	}
+	if regFullDslAppending.MatchString(line) {
+		return nil
+	}
+	if !regFullDsl.MatchString(line) {
+		return ErrMalformedDsl
+	}
+	var parts = regFullDsl.FindStringSubmatch(strings.ToLower(line))
+	var effectiveOns = []rune(parts[1])
+	var results = []rune(parts[2])
+	if len(effectiveOns) != len(results) {
+		return ErrLengthMismatch
+	}
+	for _, result := range results {
+		if _, found := FindMarkFromChar(result); !found {
+			return ErrMalformedDsl
+		}
+	}
+	if parts[3] != "" && !regFullDslAppending.MatchString(parts[3]) {
+		return ErrMalformedDsl
+	}
+	return nil
+}
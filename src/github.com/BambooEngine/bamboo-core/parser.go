@@ -53,9 +53,22 @@ const (
 	TONE_DOT   Tone = iota
 )
 
+// Modifier is a set of modifier keys which must be held with a key.
+type Modifier uint
+
+const (
+	AltGrModifier Modifier = 1 << iota
+)
+
+var modifiers = map[string]Modifier{
+	"AltGr": AltGrModifier,
+}
+
 type Rule struct {
 	Key           rune
-	Effect        uint8 // (Tone, Mark)
+	Prefix        []rune   // the keys typed right before Key, see KeyDefinition
+	Modifiers     Modifier // the modifiers held with Key
+	Effect        uint8    // (Tone, Mark)
 	EffectType    EffectType
 	EffectOn      rune
 	Result        rune
@@ -87,9 +100,8 @@ type InputMethod struct {
 }
 
 func ParseInputMethod(imDef map[string]InputMethodDefinition, imName string) InputMethod {
-	var inputMethods = parseInputMethods(imDef)
-	if inputMethod, found := inputMethods[imName]; found {
-		return inputMethod
+	if imDefinition, found := imDef[imName]; found {
+		return LookupInputMethod(imName, imDefinition)
 	}
 	return InputMethod{}
 }
@@ -100,25 +112,79 @@ func parseInputMethods(imDef map[string]InputMethodDefinition) map[string]InputM
 		var im InputMethod
 		im.Name = name
 		for keyStr, line := range imDefinition {
-			var keys = []rune(keyStr)
-			if len(keys) == 0 {
+			var keyDef, err = ParseKeyDefinition(keyStr)
+			if err != nil {
 				continue
 			}
-			var key = keys[0]
-			im.Rules = append(im.Rules, ParseRules(key, line)...)
-			if strings.Contains(strings.ToLower(line), "uo") {
+			var key = keyDef.Key
+			for _, rule := range ParseRules(key, line) {
+				im.Rules = append(im.Rules, keyDef.bind(rule))
+			}
+			if keyDef.Modifiers != 0 {
+				// a key held with a modifier does not take the plain key
+				continue
+			}
+			if strings.Contains(strings.ToLower(line), "uo") && !inKeyList(im.SuperKeys, key) {
 				im.SuperKeys = append(im.SuperKeys, key)
 			}
-			if _, ok := tones[line]; ok {
+			if _, ok := tones[line]; ok && !inKeyList(im.ToneKeys, key) {
 				im.ToneKeys = append(im.ToneKeys, key)
 			}
-			im.Keys = append(im.Keys, key)
+			if !inKeyList(im.Keys, key) {
+				im.Keys = append(im.Keys, key)
+			}
 		}
 		inputMethods[name] = im
 	}
 	return inputMethods
 }
 
+// KeyDefinition is a key of an input method definition, which is either a
+// single character ("w"), a key sequence ("uw") or a key held with modifiers
+// ("AltGr+d"). Key triggers the rules, they only apply when the keys typed
+// right before it are Prefix and when Modifiers are held with it.
+type KeyDefinition struct {
+	Key       rune
+	Prefix    []rune
+	Modifiers Modifier
+}
+
+var regModifier = regexp.MustCompile(`^([a-zA-Z]{2,})\+(.+)$`)
+
+func ParseKeyDefinition(keyStr string) (KeyDefinition, error) {
+	var keyDef KeyDefinition
+	for {
+		var parts = regModifier.FindStringSubmatch(keyStr)
+		if parts == nil {
+			break
+		}
+		var modifier, found = modifiers[parts[1]]
+		if !found {
+			return keyDef, ErrUnknownModifier
+		}
+		keyDef.Modifiers |= modifier
+		keyStr = parts[2]
+	}
+	var keys = []rune(keyStr)
+	if len(keys) == 0 {
+		return keyDef, ErrEmptyKey
+	}
+	keyDef.Key = keys[len(keys)-1]
+	if len(keys) > 1 {
+		keyDef.Prefix = keys[:len(keys)-1]
+	}
+	return keyDef, nil
+}
+
+func (k KeyDefinition) bind(rule Rule) Rule {
+	rule.Prefix = k.Prefix
+	rule.Modifiers = k.Modifiers
+	for i := range rule.AppendedRules {
+		rule.AppendedRules[i] = k.bind(rule.AppendedRules[i])
+	}
+	return rule
+}
+
 func ParseRules(key rune, line string) []Rule {
 	var rules []Rule
 	if tone, ok := tones[line]; ok {
@@ -138,3 +138,156 @@ func TestAppendRule(t *testing.T) {
 
 func TestParseRulesWithIm(t *testing.T) {
 }
+
+func TestValidateInputMethod(t *testing.T) {
+	for name := range InputMethodDefinitions {
+		if _, errs := ValidateInputMethod(InputMethodDefinitions, name); len(errs) != 0 {
+			t.Errorf("Validate built-in input method %s, got %v", name, errs)
+		}
+	}
+	var imDef = map[string]InputMethodDefinition{
+		"Broken": {
+			"s":  "DauSac",
+			"S":  "DauHuyen",
+			"x":  "DauNgaa",
+			"a":  "A-Â",
+			"w":  "UOA_ƯƠ",
+			"dd": "D_Đ",
+			"d":  "D_Đ",
+			"":   "D_Đ",
+		},
+	}
+	var im, errs = ValidateInputMethod(imDef, "Broken")
+	var expected = map[string]error{
+		"s": ErrDuplicateKey,
+		"x": ErrUnknownTone,
+		"a": ErrMalformedDsl,
+		"w": ErrLengthMismatch,
+		"":  ErrEmptyKey,
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("Validate a broken input method, expected %d errors, got %v", len(expected), errs)
+	}
+	for _, err := range errs {
+		var imErr, ok = err.(*InputMethodError)
+		if !ok {
+			t.Errorf("Validate a broken input method, expected *InputMethodError, got %T", err)
+			continue
+		}
+		if expected[imErr.Key] != imErr.Err {
+			t.Errorf("Validate key %s, expected [%v], got [%v]", imErr.Key, expected[imErr.Key], imErr.Err)
+		}
+	}
+	if len(im.Keys) != 2 {
+		t.Errorf("Validate a broken input method, expected the keys [S d], got %q", im.Keys)
+	}
+	var modified = map[string]InputMethodDefinition{"Modified": {"AltGr+d": "D_Đ", "AltGr+D": "D_Đ", "D": "D_Đ", "dD": "D_Đ", "Hyper+d": "D_Đ"}}
+	if _, errs = ValidateInputMethod(modified, "Modified"); len(errs) != 2 || errs[1].(*InputMethodError).Err != ErrUnknownModifier {
+		t.Errorf("Validate modified keys, expected AltGr+D and AltGr+d to clash and Hyper to be unknown, got %v", errs)
+	}
+	if _, errs = ValidateInputMethod(imDef, "Unknown"); len(errs) != 1 || errs[0].(*InputMethodError).Err != ErrUnknownInputMethod {
+		t.Errorf("Validate an unknown input method, got %v", errs)
+	}
+}
+
+func TestLookupInputMethod(t *testing.T) {
+	var im = LookupInputMethod("Telex", InputMethodDefinitions["Telex"])
+	if im.Name != "Telex" || len(im.Rules) != len(parseInputMethods(InputMethodDefinitions)["Telex"].Rules) {
+		t.Errorf("Lookup Telex, got %d rules", len(im.Rules))
+	}
+	var customized = InputMethodDefinition{}
+	for key, line := range InputMethodDefinitions["Telex"] {
+		customized[key] = line
+	}
+	delete(customized, "z")
+	if hashInputMethodDefinition("Telex", customized) == hashInputMethodDefinition("Telex", InputMethodDefinitions["Telex"]) {
+		t.Errorf("Hash a changed definition, expected a different hash")
+	}
+	if im = LookupInputMethod("Telex", customized); len(im.Keys) != len(customized) {
+		t.Errorf("Lookup a changed definition, expected %d keys, got %d", len(customized), len(im.Keys))
+	}
+	LookupInputMethod("Telex", InputMethodDefinitions["Telex"])
+	for i := 0; i < maxCachedInputMethods; i++ {
+		LookupInputMethod("Telex "+string(rune('a'+i)), customized)
+	}
+	if len(inputMethodCache.entries) != maxCachedInputMethods {
+		t.Errorf("Lookup %d changed definitions, expected %d cached input methods, got %d", maxCachedInputMethods, maxCachedInputMethods, len(inputMethodCache.entries))
+	}
+	var last = inputMethodCache.entries[len(inputMethodCache.entries)-1]
+	if last.hash != hashInputMethodDefinition("Telex "+string(rune('a'+maxCachedInputMethods-1)), customized) {
+		t.Errorf("Lookup a changed definition, expected it to be the most recently used")
+	}
+	for _, entry := range inputMethodCache.entries {
+		if entry.hash == hashInputMethodDefinition("Telex", InputMethodDefinitions["Telex"]) {
+			t.Errorf("Lookup %d other definitions, expected Telex to be dropped", maxCachedInputMethods)
+		}
+	}
+}
+
+func TestParseKeyDefinition(t *testing.T) {
+	var keyDef, err = ParseKeyDefinition("uow")
+	if err != nil || keyDef.Key != 'w' || string(keyDef.Prefix) != "uo" || keyDef.Modifiers != 0 {
+		t.Errorf("Parse [uow], got %+v %v", keyDef, err)
+	}
+	keyDef, err = ParseKeyDefinition("AltGr+d")
+	if err != nil || keyDef.Key != 'd' || len(keyDef.Prefix) != 0 || keyDef.Modifiers != AltGrModifier {
+		t.Errorf("Parse [AltGr+d], got %+v %v", keyDef, err)
+	}
+	// a plus which follows no modifier name is a key
+	for _, keyStr := range []string{"+", "a+", "AltGr++"} {
+		if keyDef, err = ParseKeyDefinition(keyStr); err != nil || keyDef.Key != '+' {
+			t.Errorf("Parse [%s], expected the key [+], got %+v %v", keyStr, keyDef, err)
+		}
+	}
+	if _, err = ParseKeyDefinition("Ctrl+d"); err != ErrUnknownModifier {
+		t.Errorf("Parse [Ctrl+d], expected [%v], got [%v]", ErrUnknownModifier, err)
+	}
+}
+
+func TestProcessKeySequence(t *testing.T) {
+	var ng = NewEngine(ParseInputMethod(InputMethodDefinitions, "Simple Telex"), EstdFlags)
+	var tests = map[string]string{
+		"vieetj":  "việt",
+		"nguowif": "người",
+		"tuw":     "tư",
+		"w":       "w",
+		"tow":     "tơ",
+		"toow":    "tơ",
+		"dda":     "đa",
+		"ddda":    "dda",
+	}
+	for input, expected := range tests {
+		ng.Reset()
+		ng.ProcessString(input, VietnameseMode)
+		if ng.GetProcessedString(VietnameseMode) != expected {
+			t.Errorf("Process [%s] with Simple Telex, expected [%s], got [%s]", input, expected, ng.GetProcessedString(VietnameseMode))
+		}
+	}
+}
+
+func TestProcessKeyWithModifiers(t *testing.T) {
+	var imDef = InputMethodDefinition{}
+	for key, line := range InputMethodDefinitions["Telex"] {
+		imDef[key] = line
+	}
+	imDef["AltGr+d"] = "_đ"
+	imDef["AltGr+["] = "_ư"
+	var ng = NewEngine(LookupInputMethod("Telex AltGr", imDef), EstdFlags)
+	if !ng.CanProcessKeyWithModifiers('[', AltGrModifier) || ng.CanProcessKeyWithModifiers('[', 0) {
+		t.Errorf("Can process [, expected AltGr+[ only")
+	}
+	if ng.CanProcessKeyWithModifiers('s', AltGrModifier) {
+		t.Errorf("Can process AltGr+s, expected no")
+	}
+	ng.ProcessKeyWithModifiers('d', AltGrModifier, VietnameseMode)
+	ng.ProcessKeyWithModifiers('[', AltGrModifier, VietnameseMode)
+	ng.ProcessString("ng", VietnameseMode)
+	if ng.GetProcessedString(VietnameseMode) != "đưng" {
+		t.Errorf("Process [AltGr+d AltGr+[ ng], expected [đưng], got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+	ng.Reset()
+	ng.ProcessString("d", VietnameseMode)
+	if ng.GetProcessedString(VietnameseMode) != "d" {
+		t.Errorf("Process [d], expected the plain key to be kept, got [%s]", ng.GetProcessedString(VietnameseMode))
+	}
+}
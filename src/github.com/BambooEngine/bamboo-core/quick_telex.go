@@ -0,0 +1,46 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+// The Quick Telex shortcuts of Unikey: a consonant typed twice in a row
+// becomes a pair of letters, e.g. "cc" -> "ch", "nn" -> "ng". They work with
+// any input method once EquickTelex is set.
+var quickTelexKeys = map[rune]rune{
+	'c': 'h',
+	'g': 'i',
+	'k': 'h',
+	'n': 'g',
+	'p': 'h',
+	'q': 'u',
+	't': 'h',
+}
+
+// generateQuickTelexTrans appends the second letter of a shortcut when the key
+// repeats the last letter of the composition. The appended letter keeps the
+// key as its raw key, so that the raw string and the English mode still show
+// what was typed ("cc"), and typing the key once more undoes the shortcut like
+// any doubled effect key ("ccc" -> "cc").
+func generateQuickTelexTrans(composition []*Transformation, lowerKey rune, isUpperCase bool) []*Transformation {
+	var next, found = quickTelexKeys[lowerKey]
+	if !found || len(composition) == 0 {
+		return nil
+	}
+	var last = composition[len(composition)-1]
+	if last.Rule.EffectType != Appending || last.Rule.Key != lowerKey || last.Rule.EffectOn != lowerKey {
+		return nil
+	}
+	return []*Transformation{{
+		IsUpperCase: isUpperCase,
+		Rule: Rule{
+			Key:        lowerKey,
+			EffectType: Appending,
+			EffectOn:   next,
+			Result:     next,
+		},
+	}}
+}
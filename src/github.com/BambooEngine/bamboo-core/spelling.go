@@ -10,44 +10,90 @@ package bamboo
 import (
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode"
 )
 
-var firstConsonantSeq = [3]string{
-	"b d đ g gh m n nh p ph r s t tr v z",
-	"c h k kh qu th",
-	"ch gi l ng ngh x",
+// SpellingRules is the syllable grammar the spelling trie is built from. The
+// consonants and the vowels are grouped in rows of space separated sequences,
+// CVMatrix lists the vowel rows which may follow each first consonant row and
+// VCMatrix the last consonant rows which may follow each vowel row.
+type SpellingRules struct {
+	FirstConsonants []string `json:"first_consonants"`
+	Vowels          []string `json:"vowels"`
+	LastConsonants  []string `json:"last_consonants"`
+	CVMatrix        [][]uint `json:"cv_matrix"`
+	VCMatrix        [][]uint `json:"vc_matrix"`
 }
 
-var vowelSeq = [6]string{
-	"ê i ua uê uy y",
-	"a iê oa uyê yê",
-	"â ă e o oo ô ơ oe u ư uâ uô ươ",
-	"oă",
-	"uơ",
-	"ai ao au âu ay ây eo êu ia iêu iu oai oao oay oeo oi ôi ơi ưa uây ui ưi uôi ươi ươu ưu uya uyu yêu",
+var DefaultSpellingRules = SpellingRules{
+	FirstConsonants: []string{
+		"b d đ g gh m n nh p ph r s t tr v z",
+		"c h k kh qu th",
+		"ch gi l ng ngh x",
+	},
+	Vowels: []string{
+		"ê i ua uê uy y",
+		"a iê oa uyê yê",
+		"â ă e o oo ô ơ oe u ư uâ uô ươ",
+		"oă",
+		"uơ",
+		"ai ao au âu ay ây eo êu ia iêu iu oai oao oay oeo oi ôi ơi ưa uây ui ưi uôi ươi ươu ưu uya uyu yêu",
+	},
+	LastConsonants: []string{
+		"ch nh",
+		"c ng",
+		"m n p t",
+	},
+	CVMatrix: [][]uint{
+		{0, 1, 2, 5},
+		{0, 1, 2, 3, 4, 5},
+		{0, 1, 2, 3, 5},
+	},
+	VCMatrix: [][]uint{
+		{0, 2},
+		{0, 1, 2},
+		{1, 2},
+		{1, 2},
+		{},
+		{},
+	},
 }
 
-var lastConsonantSeq = [3]string{
-	"ch nh",
-	"c ng",
-	"m n p t",
+// spellingState is the data the spelling is checked against, it is never
+// modified once stored: the writers build a new state and swap it in, so that
+// the readers need no lock.
+type spellingState struct {
+	rules SpellingRules
+	// the dictionaries added to the spelling trie, kept to rebuild it with
+	// other spelling rules
+	dictionaries []map[string]bool
+	// the words added one by one, merged into a single dictionary
+	addedWords map[string]bool
+	trie       *Node
+	// the system dictionary, compiled at build time so that its words do not
+	// have to be inserted into the trie at startup
+	dictionaryTrie *CompactTrie
 }
 
-var cvMatrix = [3][]uint{
-	{0, 1, 2, 5},
-	{0, 1, 2, 3, 4, 5},
-	{0, 1, 2, 3, 5},
-}
+var spelling atomic.Value // *spellingState
+
+// spellingMutex serializes the writers of the spelling state
+var spellingMutex sync.Mutex
 
-var vcMatrix = [6][]uint{
-	{0, 2},
-	{0, 1, 2},
-	{1, 2},
-	{1, 2},
+func getSpelling() *spellingState {
+	return spelling.Load().(*spellingState)
 }
 
-var spellingTrie = &Node{Full: false}
+// updateSpelling stores a modified copy of the spelling state.
+func updateSpelling(update func(state *spellingState)) {
+	spellingMutex.Lock()
+	defer spellingMutex.Unlock()
+	var state = *getSpelling()
+	update(&state)
+	spelling.Store(&state)
+}
 
 func buildCV(consonants []string, vowels []string) []string {
 	var ret []string
@@ -59,16 +105,6 @@ func buildCV(consonants []string, vowels []string) []string {
 	return ret
 }
 
-func generateVowels() []string {
-	var ret []string
-	for _, vRow := range vowelSeq {
-		for _, v := range strings.Split(vRow, " ") {
-			ret = append(ret, v)
-		}
-	}
-	return ret
-}
-
 func buildVC(vowels []string, consonants []string) []string {
 	var ret []string
 	for _, v := range vowels {
@@ -92,57 +128,172 @@ func buildCVC(cs1 []string, vs1 []string, cs2 []string) []string {
 }
 
 func init() {
-	for _, word := range GenerateDictionary() {
-		AddTrie(spellingTrie, []rune(word), false, false)
+	spelling.Store(&spellingState{
+		rules: DefaultSpellingRules,
+		trie:  buildSpellingTrie(DefaultSpellingRules, nil),
+	})
+}
+
+func buildSpellingTrie(rules SpellingRules, dictionaries []map[string]bool) *Node {
+	var trie = &Node{Full: false}
+	for _, word := range rules.GenerateWords() {
+		AddTrie(trie, []rune(word), false, false)
+	}
+	for _, dictionary := range dictionaries {
+		for word := range dictionary {
+			AddTrie(trie, []rune(word), true, false)
+		}
 	}
+	return trie
 }
 
+// SetSpellingRules rebuilds the spelling trie from the rules, the dictionaries
+// added so far are kept.
+func SetSpellingRules(rules SpellingRules) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	updateSpelling(func(state *spellingState) {
+		state.rules = rules
+		state.trie = buildSpellingTrie(rules, append(state.dictionaries[:len(state.dictionaries):len(state.dictionaries)], state.addedWords))
+	})
+	return nil
+}
+
+// AddDictionaryToSpellingTrie makes the spelling accept the words of the
+// dictionary. The words are inserted into a copy of the nodes on their path
+// rather than by rebuilding the trie, so that adding a learned word from a key
+// handler stays cheap; large dictionaries belong in ReplaceSpellingData.
 func AddDictionaryToSpellingTrie(dictionary map[string]bool) {
-	for word := range dictionary {
-		AddTrie(spellingTrie, []rune(word), true, false)
+	updateSpelling(func(state *spellingState) {
+		var words = make(map[string]bool, len(state.addedWords)+len(dictionary))
+		for word := range state.addedWords {
+			words[word] = true
+		}
+		var trie = copyTrie(state.trie)
+		for word := range dictionary {
+			words[word] = true
+			addTrie(trie, []rune(word), true, false, true)
+		}
+		state.addedWords = words
+		state.trie = trie
+	})
+}
+
+// SetSpellingDictionaryTrie checks the spelling against a compact trie too, it
+// is the same as adding its dictionary with AddDictionaryToSpellingTrie.
+func SetSpellingDictionaryTrie(trie *CompactTrie) {
+	updateSpelling(func(state *spellingState) {
+		state.dictionaryTrie = trie
+	})
+}
+
+// ReplaceSpellingData replaces the rules and all the dictionaries at once, so
+// that the spelling is never checked against a half loaded state.
+func ReplaceSpellingData(rules SpellingRules, dictionaryTrie *CompactTrie, dictionaries ...map[string]bool) error {
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	var trie = buildSpellingTrie(rules, dictionaries)
+	updateSpelling(func(state *spellingState) {
+		state.rules = rules
+		state.dictionaries = dictionaries
+		state.addedWords = nil
+		state.trie = trie
+		state.dictionaryTrie = dictionaryTrie
+	})
+	return nil
+}
+
+// testSpelling is TestString over the spelling trie and the compact dictionary
+// trie as if they were a single trie.
+func testSpelling(s []rune, dictionary bool) uint8 {
+	var state = getSpelling()
+	if state.dictionaryTrie == nil {
+		return TestString(state.trie, s, dictionary)
+	}
+	var chars = make([]rune, len(s))
+	for i, chr := range s {
+		chars[i] = unicode.ToLower(chr)
+	}
+	var node = FindNode(state.trie, chars)
+	var compactNode, found = state.dictionaryTrie.FindNode(chars)
+	if node == nil && !found {
+		return FindResultNotMatch
 	}
+	var full = node != nil && node.Full || found && compactNode.Full()
+	var inDictionary = node != nil && node.Dictionary || found && compactNode.Dictionary()
+	return matchResult(full, inDictionary, dictionary)
+}
+
+// CanExtendSpelling reports whether some letter can still be appended to a
+// tone-less word without breaking the spelling rules, e.g. "tin" -> "tinh".
+func CanExtendSpelling(word string) bool {
+	var state = getSpelling()
+	var chars = []rune(strings.ToLower(word))
+	if node := FindNode(state.trie, chars); node != nil && len(node.Children) > 0 {
+		return true
+	}
+	if state.dictionaryTrie == nil {
+		return false
+	}
+	var node, found = state.dictionaryTrie.FindNode(chars)
+	return found && node.Len() > 0
 }
 
 func GenerateDictionary() []string {
-	var words = generateVowels()
-	words = append(words, generateCV()...)
-	words = append(words, generateVC()...)
-	words = append(words, generateCVC()...)
+	return getSpelling().rules.GenerateWords()
+}
+
+// GenerateWords lists the tone-less syllables allowed by the rules.
+func (r SpellingRules) GenerateWords() []string {
+	var words = r.generateVowels()
+	words = append(words, r.generateCV()...)
+	words = append(words, r.generateVC()...)
+	words = append(words, r.generateCVC()...)
 	return words
 }
 
-func generateCV() []string {
+func (r SpellingRules) generateVowels() []string {
+	var ret []string
+	for _, vRow := range r.Vowels {
+		ret = append(ret, strings.Fields(vRow)...)
+	}
+	return ret
+}
+
+func (r SpellingRules) generateCV() []string {
 	var ret []string
-	for cRow, vRows := range cvMatrix {
+	for cRow, vRows := range r.CVMatrix {
 		for _, vRow := range vRows {
-			var consonants = strings.Split(firstConsonantSeq[cRow], " ")
-			var vowels = strings.Split(vowelSeq[vRow], " ")
+			var consonants = strings.Fields(r.FirstConsonants[cRow])
+			var vowels = strings.Fields(r.Vowels[vRow])
 			ret = append(ret, buildCV(consonants, vowels)...)
 		}
 	}
 	return ret
 }
 
-func generateVC() []string {
+func (r SpellingRules) generateVC() []string {
 	var ret []string
-	for vRow, cRows := range vcMatrix {
+	for vRow, cRows := range r.VCMatrix {
 		for _, cRow := range cRows {
-			var vowels = strings.Split(vowelSeq[vRow], " ")
-			var consonants = strings.Split(lastConsonantSeq[cRow], " ")
+			var vowels = strings.Fields(r.Vowels[vRow])
+			var consonants = strings.Fields(r.LastConsonants[cRow])
 			ret = append(ret, buildVC(vowels, consonants)...)
 		}
 	}
 	return ret
 }
 
-func generateCVC() []string {
+func (r SpellingRules) generateCVC() []string {
 	var ret []string
-	for c1Row, vRows := range cvMatrix {
+	for c1Row, vRows := range r.CVMatrix {
 		for _, vRow := range vRows {
-			for _, c2Row := range vcMatrix[vRow] {
-				var cs1 = strings.Split(firstConsonantSeq[c1Row], " ")
-				var vowels = strings.Split(vowelSeq[vRow], " ")
-				var cs2 = strings.Split(lastConsonantSeq[c2Row], " ")
+			for _, c2Row := range r.VCMatrix[vRow] {
+				var cs1 = strings.Fields(r.FirstConsonants[c1Row])
+				var vowels = strings.Fields(r.Vowels[vRow])
+				var cs2 = strings.Fields(r.LastConsonants[c2Row])
 				ret = append(ret, buildCVC(cs1, vowels, cs2)...)
 			}
 		}
@@ -0,0 +1,71 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SpellingRulesVersion is the version of the spelling rules file format this
+// package reads.
+const SpellingRulesVersion = 1
+
+var (
+	ErrSpellingRulesVersion = errors.New("unsupported spelling rules version")
+	ErrSpellingRulesMatrix  = errors.New("spelling rules matrix is out of range")
+)
+
+// SpellingRulesFile holds named profiles of spelling rules, e.g.
+//
+//	{"version": 1, "profiles": {"default": {"first_consonants": [...], ...}}}
+type SpellingRulesFile struct {
+	Version  int                      `json:"version"`
+	Profiles map[string]SpellingRules `json:"profiles"`
+}
+
+// ParseSpellingRules reads a spelling rules file and checks every profile.
+func ParseSpellingRules(data []byte) (map[string]SpellingRules, error) {
+	var file SpellingRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != SpellingRulesVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSpellingRulesVersion, file.Version)
+	}
+	for name, rules := range file.Profiles {
+		if err := rules.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return file.Profiles, nil
+}
+
+// Validate checks that the matrices refer to existing rows.
+func (r SpellingRules) Validate() error {
+	if len(r.CVMatrix) != len(r.FirstConsonants) || len(r.VCMatrix) != len(r.Vowels) {
+		return fmt.Errorf("%w: expected %d rows in cv_matrix and %d rows in vc_matrix",
+			ErrSpellingRulesMatrix, len(r.FirstConsonants), len(r.Vowels))
+	}
+	for cRow, vRows := range r.CVMatrix {
+		for _, vRow := range vRows {
+			if int(vRow) >= len(r.Vowels) {
+				return fmt.Errorf("%w: cv_matrix[%d] refers to vowel row %d", ErrSpellingRulesMatrix, cRow, vRow)
+			}
+		}
+	}
+	for vRow, cRows := range r.VCMatrix {
+		for _, cRow := range cRows {
+			if int(cRow) >= len(r.LastConsonants) {
+				return fmt.Errorf("%w: vc_matrix[%d] refers to last consonant row %d", ErrSpellingRulesMatrix, vRow, cRow)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// the hard-coded tables the spelling trie was built from before the rules
+// became data
+var legacyFirstConsonantSeq = [3]string{
+	"b d đ g gh m n nh p ph r s t tr v z",
+	"c h k kh qu th",
+	"ch gi l ng ngh x",
+}
+
+var legacyVowelSeq = [6]string{
+	"ê i ua uê uy y",
+	"a iê oa uyê yê",
+	"â ă e o oo ô ơ oe u ư uâ uô ươ",
+	"oă",
+	"uơ",
+	"ai ao au âu ay ây eo êu ia iêu iu oai oao oay oeo oi ôi ơi ưa uây ui ưi uôi ươi ươu ưu uya uyu yêu",
+}
+
+var legacyLastConsonantSeq = [3]string{
+	"ch nh",
+	"c ng",
+	"m n p t",
+}
+
+var legacyCVMatrix = [3][]uint{
+	{0, 1, 2, 5},
+	{0, 1, 2, 3, 4, 5},
+	{0, 1, 2, 3, 5},
+}
+
+var legacyVCMatrix = [6][]uint{
+	{0, 2},
+	{0, 1, 2},
+	{1, 2},
+	{1, 2},
+}
+
+func generateLegacyDictionary() []string {
+	var words []string
+	for _, vRow := range legacyVowelSeq {
+		words = append(words, strings.Split(vRow, " ")...)
+	}
+	for cRow, vRows := range legacyCVMatrix {
+		for _, vRow := range vRows {
+			words = append(words, buildCV(strings.Split(legacyFirstConsonantSeq[cRow], " "), strings.Split(legacyVowelSeq[vRow], " "))...)
+		}
+	}
+	for vRow, cRows := range legacyVCMatrix {
+		for _, cRow := range cRows {
+			words = append(words, buildVC(strings.Split(legacyVowelSeq[vRow], " "), strings.Split(legacyLastConsonantSeq[cRow], " "))...)
+		}
+	}
+	for c1Row, vRows := range legacyCVMatrix {
+		for _, vRow := range vRows {
+			for _, c2Row := range legacyVCMatrix[vRow] {
+				var cs1 = strings.Split(legacyFirstConsonantSeq[c1Row], " ")
+				var vowels = strings.Split(legacyVowelSeq[vRow], " ")
+				var cs2 = strings.Split(legacyLastConsonantSeq[c2Row], " ")
+				words = append(words, buildCVC(cs1, vowels, cs2)...)
+			}
+		}
+	}
+	return words
+}
+
+func TestDefaultSpellingRulesMatchLegacyTrie(t *testing.T) {
+	var legacyWords = generateLegacyDictionary()
+	var words = DefaultSpellingRules.GenerateWords()
+	sort.Strings(legacyWords)
+	sort.Strings(words)
+	if !reflect.DeepEqual(words, legacyWords) {
+		t.Fatalf("Default spelling rules generate %d words, expected the %d legacy words", len(words), len(legacyWords))
+	}
+	var legacyTrie = &Node{Full: false}
+	for _, word := range legacyWords {
+		AddTrie(legacyTrie, []rune(word), false, false)
+	}
+	if !reflect.DeepEqual(buildSpellingTrie(DefaultSpellingRules, nil), legacyTrie) {
+		t.Errorf("Default spelling trie differs from the legacy trie")
+	}
+}
+
+func TestParseSpellingRules(t *testing.T) {
+	var profiles, err = ParseSpellingRules([]byte(`{"version": 1, "profiles": {"tiny": {
+		"first_consonants": ["b m"], "vowels": ["a o"], "last_consonants": ["n"],
+		"cv_matrix": [[0]], "vc_matrix": [[0]]}}}`))
+	if err != nil {
+		t.Fatalf("Parse spelling rules: %v", err)
+	}
+	if words := profiles["tiny"].GenerateWords(); len(words) != 12 {
+		t.Errorf("Tiny profile words, expected [12], got %q", words)
+	}
+	if _, err = ParseSpellingRules([]byte(`{"version": 2, "profiles": {}}`)); !errors.Is(err, ErrSpellingRulesVersion) {
+		t.Errorf("Parse a newer version, expected [%v], got [%v]", ErrSpellingRulesVersion, err)
+	}
+	_, err = ParseSpellingRules([]byte(`{"version": 1, "profiles": {"bad": {
+		"first_consonants": ["b"], "vowels": ["a"], "last_consonants": ["n"],
+		"cv_matrix": [[1]], "vc_matrix": [[0]]}}}`))
+	if !errors.Is(err, ErrSpellingRulesMatrix) {
+		t.Errorf("Parse a bad matrix, expected [%v], got [%v]", ErrSpellingRulesMatrix, err)
+	}
+}
+
+func TestSetSpellingRules(t *testing.T) {
+	defer SetSpellingRules(DefaultSpellingRules)
+	var rules = DefaultSpellingRules
+	rules.FirstConsonants = append([]string{}, rules.FirstConsonants...)
+	rules.FirstConsonants = append(rules.FirstConsonants, "kr")
+	if err := SetSpellingRules(rules); err == nil {
+		t.Errorf("Set spelling rules without a cv_matrix row, expected an error")
+	}
+	rules.CVMatrix = append(append([][]uint{}, rules.CVMatrix...), []uint{2})
+	if err := SetSpellingRules(rules); err != nil {
+		t.Fatalf("Set spelling rules: %v", err)
+	}
+	if testSpelling([]rune("krông"), false) != FindResultMatchFull {
+		t.Errorf("Test spelling of krông with the kr cluster, expected a full match")
+	}
+	AddDictionaryToSpellingTrie(map[string]bool{"đắk": true})
+	if err := SetSpellingRules(DefaultSpellingRules); err != nil {
+		t.Fatalf("Set default spelling rules: %v", err)
+	}
+	if testSpelling([]rune("krông"), false) != FindResultNotMatch {
+		t.Errorf("Test spelling of krông with the default rules, expected no match")
+	}
+	if testSpelling([]rune("đắk"), true) != FindResultMatchFull {
+		t.Errorf("Test spelling of đắk after a rebuild, expected the dictionary word to be kept")
+	}
+}
@@ -1,6 +1,7 @@
 package bamboo
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -29,3 +30,70 @@ func TestGenerateSoundsFromWord(t *testing.T) {
 		t.Errorf("Test length of chuyển, expected [4], got [%v]", len(s4))
 	}
 }
+
+func TestCanExtendSpelling(t *testing.T) {
+	if !CanExtendSpelling("tin") {
+		t.Errorf("Test extending tin, expected [true], got [false]")
+	}
+	if CanExtendSpelling("viêt") {
+		t.Errorf("Test extending viêt, expected [false], got [true]")
+	}
+	if CanExtendSpelling("xyz") {
+		t.Errorf("Test extending xyz, expected [false], got [true]")
+	}
+}
+
+func TestSpellingConcurrentUpdates(t *testing.T) {
+	var state = getSpelling()
+	defer ReplaceSpellingData(state.rules, state.dictionaryTrie, state.dictionaries...)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			AddDictionaryToSpellingTrie(map[string]bool{"krông": true})
+			ReplaceSpellingData(DefaultSpellingRules, nil, map[string]bool{"đắk": true})
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if testSpelling([]rune("tiêng"), false) != FindResultMatchFull {
+			t.Fatalf("Test spelling of tiêng while the data is replaced, expected a full match")
+		}
+		CanExtendSpelling("tin")
+	}
+	wg.Wait()
+	if testSpelling([]rune("đắk"), true) != FindResultMatchFull || testSpelling([]rune("krông"), true) != FindResultNotMatch {
+		t.Errorf("Test spelling after the data was replaced, expected only the last dictionary")
+	}
+}
+
+func TestAddDictionaryToSpellingTrie(t *testing.T) {
+	var state = getSpelling()
+	defer ReplaceSpellingData(state.rules, state.dictionaryTrie, state.dictionaries...)
+	AddDictionaryToSpellingTrie(map[string]bool{"krông": true})
+	AddDictionaryToSpellingTrie(map[string]bool{"đắk": true})
+	if FindNode(state.trie, []rune("krông")) != nil {
+		t.Errorf("Test the trie read before adding krông, expected it unchanged")
+	}
+	if testSpelling([]rune("krông"), true) != FindResultMatchFull || testSpelling([]rune("đắk"), true) != FindResultMatchFull {
+		t.Errorf("Test spelling of the added words, expected full matches")
+	}
+	if testSpelling([]rune("krong"), false) != FindResultMatchPrefix {
+		t.Errorf("Test spelling of krong, expected a prefix of krông")
+	}
+	if len(getSpelling().addedWords) != 2 {
+		t.Errorf("Test the added words, expected a single dictionary of 2 words, got %v", getSpelling().addedWords)
+	}
+	SetSpellingRules(DefaultSpellingRules)
+	if testSpelling([]rune("krông"), true) != FindResultMatchFull {
+		t.Errorf("Test spelling of krông after the rules changed, expected a full match")
+	}
+}
+
+func BenchmarkAddDictionaryToSpellingTrie(b *testing.B) {
+	var state = getSpelling()
+	defer ReplaceSpellingData(state.rules, state.dictionaryTrie, state.dictionaries...)
+	for i := 0; i < b.N; i++ {
+		AddDictionaryToSpellingTrie(map[string]bool{"krông": true})
+	}
+}
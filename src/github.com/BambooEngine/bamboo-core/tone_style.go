@@ -0,0 +1,179 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ConvertToneStyle moves the tone of every Vietnamese syllable in the text to
+// the position of the given style, e.g. "hoà" -> "hòa" when stdStyle is true
+// and "hòa" -> "hoà" otherwise. Words which do not pass the spelling rules are
+// left untouched. The decomposed forms (NFD) keep their combining marks.
+func ConvertToneStyle(text string, stdStyle bool) string {
+	var output strings.Builder
+	var word []rune
+	for _, chr := range text {
+		if unicode.IsLetter(chr) || len(word) > 0 && unicode.Is(unicode.Mn, chr) {
+			word = append(word, chr)
+			continue
+		}
+		output.WriteString(convertWordToneStyle(word, stdStyle))
+		output.WriteRune(chr)
+		word = word[:0]
+	}
+	output.WriteString(convertWordToneStyle(word, stdStyle))
+	return output.String()
+}
+
+func convertWordToneStyle(word []rune, stdStyle bool) string {
+	var letters, marksDecomposed, toneDecomposed, ok = composeWord(word)
+	if !ok {
+		return string(word)
+	}
+	var tone = TONE_NONE
+	var composition []*Transformation
+	for _, chr := range letters {
+		var lowerChr = unicode.ToLower(chr)
+		if t := FindToneFromChar(lowerChr); t != TONE_NONE {
+			if tone != TONE_NONE {
+				// more than one tone, not a Vietnamese syllable
+				return string(word)
+			}
+			tone = t
+			lowerChr = AddToneToChar(lowerChr, 0)
+		}
+		composition = append(composition, &Transformation{
+			Rule: Rule{
+				Key:        RemoveMarkFromChar(lowerChr),
+				EffectType: Appending,
+				EffectOn:   lowerChr,
+				Result:     lowerChr,
+			},
+			IsUpperCase: unicode.IsUpper(chr),
+		})
+	}
+	if tone == TONE_NONE {
+		return string(word)
+	}
+	if getSpellingMatchResult(composition, ToneLess|LowerCase, false) != FindResultMatchFull {
+		return string(word)
+	}
+	var target = findToneTarget(composition, stdStyle)
+	if target == nil {
+		return string(word)
+	}
+	var result []rune
+	for i, trans := range composition {
+		var chr = trans.Rule.EffectOn
+		if trans == target {
+			chr = AddToneToChar(chr, uint8(tone))
+		}
+		if trans.IsUpperCase {
+			chr = unicode.ToUpper(chr)
+		}
+		result = appendDecomposed(result, chr, marksDecomposed[i], toneDecomposed)
+	}
+	return string(result)
+}
+
+// the combining marks of the decomposed forms by tone and mark, e.g. "ệ" is
+// "e\u0323\u0302"
+var combiningTones = []rune{
+	TONE_GRAVE: '\u0300',
+	TONE_ACUTE: '\u0301',
+	TONE_HOOK:  '\u0309',
+	TONE_TILDE: '\u0303',
+	TONE_DOT:   '\u0323',
+}
+
+var combiningMarks = []rune{
+	MARK_HAT:   '\u0302',
+	MARK_BREVE: '\u0306',
+	MARK_HORN:  '\u031b',
+}
+
+// composeWord composes the combining marks of the word with their letters, it
+// tells which letters had a decomposed mark and whether the tone was
+// decomposed. A word with a mark which is not a Vietnamese one can not be
+// composed.
+func composeWord(word []rune) (letters []rune, marksDecomposed []bool, toneDecomposed bool, ok bool) {
+	for _, chr := range word {
+		var tone, mark = findIndexRune(combiningTones, chr), findIndexRune(combiningMarks, chr)
+		var isTone, isMark = tone > 0, mark > 0
+		if !isTone && !isMark {
+			if unicode.Is(unicode.Mn, chr) {
+				return nil, nil, false, false
+			}
+			letters = append(letters, chr)
+			marksDecomposed = append(marksDecomposed, false)
+			continue
+		}
+		var last = len(letters) - 1
+		var lowerChr = unicode.ToLower(letters[last])
+		var composed rune
+		if isTone {
+			if FindVowelPosition(lowerChr) < 0 || FindToneFromChar(lowerChr) != TONE_NONE {
+				return nil, nil, false, false
+			}
+			composed = AddToneToChar(lowerChr, uint8(tone))
+			toneDecomposed = true
+		} else {
+			if FindMarkPosition(AddToneToChar(lowerChr, 0)) != 0 {
+				return nil, nil, false, false
+			}
+			if composed = AddMarkToChar(lowerChr, uint8(mark)); composed == 0 {
+				return nil, nil, false, false
+			}
+			marksDecomposed[last] = true
+		}
+		if unicode.IsUpper(letters[last]) {
+			composed = unicode.ToUpper(composed)
+		}
+		letters[last] = composed
+	}
+	return letters, marksDecomposed, toneDecomposed, true
+}
+
+// appendDecomposed appends the letter with its mark and tone decomposed as
+// they were in the word, in the canonical order of NFD.
+func appendDecomposed(result []rune, chr rune, markDecomposed, toneDecomposed bool) []rune {
+	if !markDecomposed && !toneDecomposed {
+		return append(result, chr)
+	}
+	var isUpper = unicode.IsUpper(chr)
+	var lowerChr = unicode.ToLower(chr)
+	var tone = FindToneFromChar(lowerChr)
+	var base = AddToneToChar(lowerChr, 0)
+	var mark = MARK_NONE
+	if markDecomposed {
+		mark = Mark(FindMarkPosition(base))
+		base = RemoveMarkFromChar(base)
+	}
+	if !toneDecomposed {
+		base = AddToneToChar(base, uint8(tone))
+	}
+	if isUpper {
+		base = unicode.ToUpper(base)
+	}
+	result = append(result, base)
+	if mark == MARK_HORN {
+		result = append(result, '\u031b')
+	}
+	if toneDecomposed && tone == TONE_DOT {
+		result = append(result, '\u0323')
+	}
+	if mark == MARK_HAT || mark == MARK_BREVE {
+		result = append(result, combiningMarks[mark])
+	}
+	if toneDecomposed && tone != TONE_NONE && tone != TONE_DOT {
+		result = append(result, combiningTones[tone])
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"testing"
+)
+
+func TestConvertToneStyle(t *testing.T) {
+	var tests = []struct {
+		input    string
+		stdStyle bool
+		expected string
+	}{
+		{"hoà", true, "hòa"},
+		{"hòa", false, "hoà"},
+		{"Hoà bình", true, "Hòa bình"},
+		{"HOÀ", true, "HÒA"},
+		{"thuỷ", true, "thủy"},
+		{"thủy", false, "thuỷ"},
+		{"khoẻ", true, "khỏe"},
+		{"hoàng", true, "hoàng"},
+		{"hòang", true, "hoàng"},
+		{"thuở", true, "thuở"},
+		{"quý", true, "quý"},
+		{"quý", false, "quý"},
+		{"giữ", true, "giữ"},
+		{"tiếng việt", false, "tiếng việt"},
+		{"chuyện", true, "chuyện"},
+		{"mùa thuà", true, "mùa thùa"},
+		{"hoà, tuỳ; loà!", true, "hòa, tùy; lòa!"},
+		{"café", true, "café"},
+		{"hoàà", true, "hoàà"},
+		{"123 hoà", true, "123 hòa"},
+		{"ho\u0300a", true, "ho\u0300a"},
+		{"hoa\u0300 bi\u0300nh", true, "ho\u0300a bi\u0300nh"},
+		{"tho\u031b\u0309", true, "tho\u031b\u0309"},
+		{"khoe\u0309", true, "kho\u0309e"},
+		{"Thu\u0309y", false, "Thuy\u0309"},
+		{"chuye\u0323\u0302n", true, "chuye\u0323\u0302n"},
+		{"cafe\u0301", true, "cafe\u0301"},
+		{"ho\u0300a\u0300", true, "ho\u0300a\u0300"},
+		{"", true, ""},
+	}
+	for _, test := range tests {
+		if out := ConvertToneStyle(test.input, test.stdStyle); out != test.expected {
+			t.Errorf("Convert [%s] (std style: %v), expected [%s], got [%s]", test.input, test.stdStyle, test.expected, out)
+		}
+	}
+}
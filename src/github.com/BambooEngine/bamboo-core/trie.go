@@ -24,42 +24,61 @@ type Node struct {
 }
 
 func AddTrie(trie *Node, s []rune, dictionary bool, down bool) {
+	addTrie(trie, s, dictionary, down, false)
+}
+
+// copyTrie returns a copy of the node which shares its children.
+func copyTrie(trie *Node) *Node {
+	var node = &Node{Full: trie.Full, Dictionary: trie.Dictionary, Children: make(map[rune]*Node, len(trie.Children)+1)}
+	for chr, child := range trie.Children {
+		node.Children[chr] = child
+	}
+	return node
+}
+
+// addTrie is AddTrie, with copyNodes set the children are copied before they are
+// modified so that a trie which is being read can be extended: trie must then
+// be a copy already, see copyTrie.
+func addTrie(trie *Node, s []rune, dictionary bool, down bool, copyNodes bool) {
 	if trie.Children == nil {
 		trie.Children = map[rune]*Node{}
 	}
+	var child = func(chr rune) *Node {
+		var node = trie.Children[chr]
+		if node == nil {
+			node = &Node{}
+		} else if copyNodes {
+			node = copyTrie(node)
+		}
+		trie.Children[chr] = node
+		return node
+	}
 
 	//add original char
 	s0 := s[0]
-	if trie.Children[s0] == nil {
-		trie.Children[s0] = &Node{}
-	}
-
+	var node = child(s0)
 	if len(s) == 1 {
-		if !trie.Children[s0].Full {
-			trie.Children[s0].Full = !down
+		if !node.Full {
+			node.Full = !down
 		}
-		trie.Children[s0].Dictionary = dictionary
+		node.Dictionary = dictionary
 	} else {
-		AddTrie(trie.Children[s0], s[1:], dictionary, down)
+		addTrie(node, s[1:], dictionary, down, copyNodes)
 	}
 
 	//add down 1 level char
 	var r0 = RemoveMarkFromChar(s0)
 	if r0 != s0 {
-		if trie.Children[r0] == nil {
-			trie.Children[r0] = &Node{}
-		}
+		node = child(r0)
 		if len(s) > 1 {
-			AddTrie(trie.Children[r0], s[1:], dictionary, true)
+			addTrie(node, s[1:], dictionary, true, copyNodes)
 		}
 	}
 	var r1 = AddToneToChar(r0, uint8(TONE_NONE))
 	if r1 != s0 && r1 != r0 {
-		if trie.Children[r1] == nil {
-			trie.Children[r1] = &Node{}
-		}
+		node = child(r1)
 		if len(s) > 1 {
-			AddTrie(trie.Children[r1], s[1:], dictionary, true)
+			addTrie(node, s[1:], dictionary, true, copyNodes)
 		}
 	}
 }
@@ -150,6 +150,21 @@ func inKeyList(keys []rune, key rune) bool {
 	return false
 }
 
+// hasKeyPrefix tells whether the lower-case keys end with the prefix of a key
+// sequence.
+func hasKeyPrefix(keys []rune, prefix []rune) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+	var tail = keys[len(keys)-len(prefix):]
+	for i, key := range prefix {
+		if unicode.ToLower(key) != tail[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func FindToneFromChar(chr rune) Tone {
 	pos := FindVowelPosition(chr)
 	if pos == -1 {
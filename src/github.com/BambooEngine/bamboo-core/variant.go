@@ -0,0 +1,127 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"unicode"
+)
+
+var variantTones = []Tone{TONE_NONE, TONE_ACUTE, TONE_GRAVE, TONE_HOOK, TONE_TILDE, TONE_DOT}
+
+// GenerateVariants lists every tone and mark variant of a syllable which passes
+// the spelling rules, e.g. "ma" -> "ma", "má", "mà", "mả", "mã", "mạ"... The
+// tones are placed with the given style and tones which do not go with the
+// final consonant are skipped, "mát" has no "màt" variant.
+func GenerateVariants(word string, stdStyle bool) []string {
+	var chars = []rune(word)
+	if len(chars) == 0 {
+		return nil
+	}
+	var bases = make([]rune, len(chars))
+	for i, chr := range chars {
+		bases[i] = RemoveMarkFromChar(AddToneToChar(unicode.ToLower(chr), 0))
+	}
+	var variants []string
+	for _, marked := range generateMarkVariants(bases) {
+		var composition = buildVariantComposition(marked)
+		if getSpellingMatchResult(composition, ToneLess|LowerCase, false) != FindResultMatchFull {
+			continue
+		}
+		var target = findToneTarget(composition, stdStyle)
+		for _, tone := range variantTones {
+			if tone != TONE_NONE && (target == nil || !haveValidTone(composition, tone)) {
+				continue
+			}
+			var result = make([]rune, len(marked))
+			for i, trans := range composition {
+				result[i] = trans.Rule.EffectOn
+				if trans == target {
+					result[i] = AddToneToChar(result[i], uint8(tone))
+				}
+			}
+			variants = append(variants, string(result))
+		}
+	}
+	return variants
+}
+
+func generateMarkVariants(bases []rune) [][]rune {
+	var variants = [][]rune{nil}
+	for _, base := range bases {
+		var family = getMarkFamily(base)
+		if len(family) == 0 {
+			family = []rune{base}
+		}
+		var next [][]rune
+		for _, variant := range variants {
+			for _, chr := range family {
+				next = append(next, append(append([]rune{}, variant...), chr))
+			}
+		}
+		variants = next
+	}
+	return variants
+}
+
+func buildVariantComposition(chars []rune) []*Transformation {
+	var composition []*Transformation
+	for _, chr := range chars {
+		composition = append(composition, &Transformation{
+			Rule: Rule{
+				Key:        RemoveMarkFromChar(chr),
+				EffectType: Appending,
+				EffectOn:   chr,
+				Result:     chr,
+			},
+		})
+	}
+	return composition
+}
+
+// buildComposition turns a word back into the transformations the input method
+// would have produced, so that the word can be edited further as if it had
+// been typed. Effects which have no key in the input method get a virtual one.
+func (e *BambooEngine) buildComposition(word string) []*Transformation {
+	var composition []*Transformation
+	for _, chr := range []rune(word) {
+		var isUpperCase = unicode.IsUpper(chr)
+		var lowerChr = unicode.ToLower(chr)
+		var tone = FindToneFromChar(lowerChr)
+		var marked = AddToneToChar(lowerChr, 0)
+		var base = RemoveMarkFromChar(marked)
+		var appending = newAppendingTrans(base, isUpperCase)
+		composition = append(composition, appending)
+		if marked != base {
+			var mark, _ = FindMarkFromChar(marked)
+			composition = append(composition, &Transformation{
+				Rule:   e.findEffectRule(MarkTransformation, uint8(mark), base),
+				Target: appending,
+			})
+		}
+		if tone != TONE_NONE {
+			composition = append(composition, &Transformation{
+				Rule:   e.findEffectRule(ToneTransformation, uint8(tone), 0),
+				Target: appending,
+			})
+		}
+	}
+	return composition
+}
+
+func (e *BambooEngine) findEffectRule(effectType EffectType, effect uint8, effectOn rune) Rule {
+	for _, rule := range e.inputMethod.Rules {
+		if rule.EffectType == effectType && rule.Effect == effect && (effectType == ToneTransformation || rule.EffectOn == effectOn) {
+			return rule
+		}
+	}
+	return Rule{
+		EffectType: effectType,
+		Effect:     effect,
+		EffectOn:   effectOn,
+	}
+}
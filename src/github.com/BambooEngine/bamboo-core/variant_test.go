@@ -0,0 +1,61 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This software is licensed under the MIT license. For more information,
+ * see <https://github.com/BambooEngine/bamboo-core/blob/master/LISENCE>.
+ */
+package bamboo
+
+import (
+	"testing"
+)
+
+func TestGenerateVariants(t *testing.T) {
+	var variants = GenerateVariants("ma", true)
+	for _, expected := range []string{"ma", "má", "mà", "mả", "mã", "mạ"} {
+		if !containsString(variants, expected) {
+			t.Errorf("GenerateVariants(ma), expected %s in %v", expected, variants)
+		}
+	}
+	variants = GenerateVariants("mát", true)
+	for _, expected := range []string{"mát", "mạt", "mắt", "mặt", "mất", "mật"} {
+		if !containsString(variants, expected) {
+			t.Errorf("GenerateVariants(mát), expected %s in %v", expected, variants)
+		}
+	}
+	for _, unexpected := range []string{"màt", "mảt", "mãt", "mằt"} {
+		if containsString(variants, unexpected) {
+			t.Errorf("GenerateVariants(mát), unexpected %s in %v", unexpected, variants)
+		}
+	}
+	if variants = GenerateVariants("hoa", true); !containsString(variants, "hòa") || containsString(variants, "hoà") {
+		t.Errorf("GenerateVariants(hoa) with std style, got %v", variants)
+	}
+	if variants = GenerateVariants("hoa", false); !containsString(variants, "hoà") || containsString(variants, "hòa") {
+		t.Errorf("GenerateVariants(hoa) with old style, got %v", variants)
+	}
+	if variants = GenerateVariants("tu", true); !containsString(variants, "từ") || !containsString(variants, "tủ") {
+		t.Errorf("GenerateVariants(tu), got %v", variants)
+	}
+}
+
+func TestReplaceLastWord(t *testing.T) {
+	var e = NewEngine(ParseInputMethod(InputMethodDefinitions, "Telex"), EstdFlags)
+	e.ProcessString("dde ma", VietnameseMode)
+	e.ReplaceLastWord("Mặt")
+	if e.GetProcessedString(VietnameseMode) != "Mặt" {
+		t.Errorf("ReplaceLastWord(Mặt), got [%s]", e.GetProcessedString(VietnameseMode))
+	}
+	if e.GetRawString() != "dde mawjt" {
+		t.Errorf("ReplaceLastWord(Mặt), expected raw string [dde mawjt], got [%s]", e.GetRawString())
+	}
+	e.ProcessKey('s', VietnameseMode)
+	if e.GetProcessedString(VietnameseMode) != "Mắt" {
+		t.Errorf("ReplaceLastWord(Mặt) then s, expected [Mắt], got [%s]", e.GetProcessedString(VietnameseMode))
+	}
+	e.RemoveLastChar()
+	if e.GetProcessedString(VietnameseMode) != "Mắ" {
+		t.Errorf("ReplaceLastWord(Mặt) then remove a char, expected [Mắ], got [%s]", e.GetProcessedString(VietnameseMode))
+	}
+}
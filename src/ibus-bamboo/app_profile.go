@@ -0,0 +1,184 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+)
+
+// AppProfile overrides the global settings of Config for the windows of an
+// application, Config.AppProfiles is keyed by WM_CLASS as the white lists are.
+// Empty fields keep the global settings.
+type AppProfile struct {
+	InputMethod   string `json:",omitempty"`
+	OutputCharset string `json:",omitempty"`
+	Flags         *uint  `json:",omitempty"`
+	IBflags       *uint  `json:",omitempty"`
+	EnglishMode   *bool  `json:",omitempty"` // the state of the Vietnamese/English switch until it is toggled in the application
+}
+
+// pick keeps the fields which are set in fields.
+func (p AppProfile) pick(fields AppProfile) AppProfile {
+	var picked AppProfile
+	if fields.InputMethod != "" {
+		picked.InputMethod = p.InputMethod
+	}
+	if fields.OutputCharset != "" {
+		picked.OutputCharset = p.OutputCharset
+	}
+	if fields.Flags != nil {
+		picked.Flags = p.Flags
+	}
+	if fields.IBflags != nil {
+		picked.IBflags = p.IBflags
+	}
+	if fields.EnglishMode != nil {
+		picked.EnglishMode = p.EnglishMode
+	}
+	return picked
+}
+
+// getSettings returns the settings in use as a full profile.
+func (e *IBusBambooEngine) getSettings() AppProfile {
+	var flags, ibFlags, englishMode = e.config.Flags, e.config.IBflags, e.englishMode
+	return AppProfile{
+		InputMethod:   e.config.InputMethod,
+		OutputCharset: e.config.OutputCharset,
+		Flags:         &flags,
+		IBflags:       &ibFlags,
+		EnglishMode:   &englishMode,
+	}
+}
+
+func (e *IBusBambooEngine) setSettings(p AppProfile) {
+	p.applyTo(e.config)
+	if p.EnglishMode != nil {
+		e.englishMode = *p.EnglishMode
+	}
+}
+
+// applyTo sets the fields of the configuration which the profile overrides.
+func (p AppProfile) applyTo(c *Config) {
+	if _, found := c.InputMethodDefinitions[p.InputMethod]; found {
+		c.InputMethod = p.InputMethod
+	}
+	if isValidCharset(p.OutputCharset) {
+		c.OutputCharset = p.OutputCharset
+	}
+	if p.Flags != nil {
+		c.Flags = *p.Flags
+	}
+	if p.IBflags != nil {
+		c.IBflags = *p.IBflags
+	}
+}
+
+// restoreGlobalSettings takes back the settings which the profile in use
+// overrides.
+func (e *IBusBambooEngine) restoreGlobalSettings() {
+	if e.appProfile == nil {
+		return
+	}
+	e.setSettings(e.globalSettings.pick(*e.appProfile))
+	e.appProfile = nil
+}
+
+// applyAppProfile switches to the profile of the focused application, or back
+// to the global settings when it has none.
+func (e *IBusBambooEngine) applyAppProfile() {
+	var oldInputMethod, oldFlags = e.config.InputMethod, e.config.Flags
+	e.restoreGlobalSettings()
+	if profile, found := e.config.AppProfiles[e.wmClasses]; found {
+		e.globalSettings = e.getSettings()
+		e.setSettings(profile)
+		e.appProfile = &profile
+	}
+	e.updatePreeditor(oldInputMethod, oldFlags)
+	e.propList = GetPropListByConfig(e.config)
+}
+
+// saveAppProfile keeps the settings in use as the profile of the application.
+func (e *IBusBambooEngine) saveAppProfile(wmClasses string) {
+	var profile = e.getSettings()
+	if e.config.AppProfiles == nil {
+		e.config.AppProfiles = map[string]AppProfile{}
+	}
+	e.config.AppProfiles[wmClasses] = profile
+	if wmClasses != e.wmClasses {
+		return
+	}
+	if e.appProfile != nil {
+		// the Vietnamese/English switch goes back to its state before the focus
+		e.setSettings(e.globalSettings.pick(AppProfile{EnglishMode: profile.EnglishMode}))
+	}
+	e.restoreGlobalSettings()
+	e.globalSettings = e.getSettings()
+	e.setSettings(profile)
+	e.appProfile = &profile
+}
+
+// deleteAppProfile brings the application back to the global settings.
+func (e *IBusBambooEngine) deleteAppProfile(wmClasses string) {
+	var oldInputMethod, oldFlags = e.config.InputMethod, e.config.Flags
+	if wmClasses == e.wmClasses {
+		e.restoreGlobalSettings()
+	}
+	delete(e.config.AppProfiles, wmClasses)
+	e.updatePreeditor(oldInputMethod, oldFlags)
+}
+
+// saveConfig saves the configuration file. While a profile is in use, the
+// settings it overrides are saved to the profile and the file keeps the global
+// ones.
+func (e *IBusBambooEngine) saveConfig() {
+	SaveConfig(e.configToSave(), e.engineName)
+}
+
+// configToSave returns the configuration as it is saved: the live one, or a
+// copy of it with the global settings while a profile is in use, whose own
+// settings are updated.
+func (e *IBusBambooEngine) configToSave() *Config {
+	if e.appProfile == nil {
+		return e.config
+	}
+	var profile = e.getSettings().pick(*e.appProfile)
+	var config = *e.config
+	config.AppProfiles = make(map[string]AppProfile, len(e.config.AppProfiles))
+	for wmClasses, p := range e.config.AppProfiles {
+		config.AppProfiles[wmClasses] = p
+	}
+	config.AppProfiles[e.wmClasses] = profile
+	e.globalSettings.pick(profile).applyTo(&config)
+	e.config.AppProfiles = config.AppProfiles
+	e.appProfile = &profile
+	return &config
+}
+
+// updatePreeditor follows a change of the input method or of its flags, most
+// of the options do not touch the preeditor, so it keeps its composition.
+func (e *IBusBambooEngine) updatePreeditor(oldInputMethod string, oldFlags uint) {
+	if e.config.InputMethod != oldInputMethod {
+		var inputMethod, errs = parseInputMethod(e.config)
+		e.preeditor = bamboo.NewEngine(inputMethod, e.config.Flags)
+		e.showInputMethodErrors(errs)
+	} else if e.config.Flags != oldFlags {
+		e.preeditor.SetFlag(e.config.Flags)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BambooEngine/goibus/ibus"
+)
+
+func newAppProfileTestEngine() *IBusBambooEngine {
+	var englishMode = true
+	var ibFlags uint = IBstdFlags &^ (IBspellChecking | IBautoNonVnRestore)
+	var e = newTestEngine(IBstdFlags)
+	e.config.AppProfiles = map[string]AppProfile{
+		"xterm:XTerm":   {EnglishMode: &englishMode},
+		"ketoan:KeToan": {OutputCharset: "TCVN3 (ABC)"},
+		"code:Code":     {InputMethod: "VNI", IBflags: &ibFlags},
+	}
+	return e
+}
+
+func focusApp(e *IBusBambooEngine, wmClasses string) {
+	e.wmClasses = wmClasses
+	e.applyAppProfile()
+}
+
+func TestApplyAppProfile(t *testing.T) {
+	var e = newAppProfileTestEngine()
+	focusApp(e, "xterm:XTerm")
+	if handled, _ := e.ProcessKeyEvent('a', 0, 0); handled || !e.englishMode {
+		t.Errorf("Type in the terminal, expected the English mode")
+	}
+	focusApp(e, "gedit:Gedit")
+	if e.englishMode {
+		t.Errorf("Leave the terminal, expected the Vietnamese mode back")
+	}
+	focusApp(e, "ketoan:KeToan")
+	if e.config.OutputCharset != "TCVN3 (ABC)" {
+		t.Errorf("Focus the accounting app, expected [TCVN3 (ABC)], got [%s]", e.config.OutputCharset)
+	}
+	focusApp(e, "code:Code")
+	if e.config.OutputCharset != "Unicode" || e.config.InputMethod != "VNI" || e.config.IBflags&IBspellChecking != 0 {
+		t.Errorf("Focus the IDE, expected Unicode, VNI and no spell checking, got %s %s %b", e.config.OutputCharset, e.config.InputMethod, e.config.IBflags)
+	}
+	typeString(e, "a1")
+	if e.getPreeditString() != "á" {
+		t.Errorf("Type [a1] in the IDE, expected [á], got [%s]", e.getPreeditString())
+	}
+	e.resetPreedit()
+	focusApp(e, "gedit:Gedit")
+	if e.config.InputMethod != "Telex" || e.config.IBflags != IBstdFlags {
+		t.Errorf("Leave the IDE, expected the global settings back, got %s %b", e.config.InputMethod, e.config.IBflags)
+	}
+	typeString(e, "as")
+	if e.getPreeditString() != "á" {
+		t.Errorf("Type [as] after leaving the IDE, expected [á], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestSaveAppProfile(t *testing.T) {
+	var e = newAppProfileTestEngine()
+	focusApp(e, "code:Code")
+	e.englishMode = true
+	e.saveAppProfile("code:Code")
+	var profile = e.config.AppProfiles["code:Code"]
+	if profile.InputMethod != "VNI" || profile.EnglishMode == nil || !*profile.EnglishMode || profile.OutputCharset != "Unicode" {
+		t.Errorf("Save the profile of the IDE, got %+v", profile)
+	}
+	focusApp(e, "gedit:Gedit")
+	if e.config.InputMethod != "Telex" || e.englishMode {
+		t.Errorf("Leave the IDE, expected Telex in the Vietnamese mode, got %s english=%v", e.config.InputMethod, e.englishMode)
+	}
+	focusApp(e, "code:Code")
+	if e.config.InputMethod != "VNI" || !e.englishMode {
+		t.Errorf("Focus the IDE again, expected VNI in the English mode, got %s english=%v", e.config.InputMethod, e.englishMode)
+	}
+	e.deleteAppProfile("code:Code")
+	if _, found := e.config.AppProfiles["code:Code"]; found || e.config.InputMethod != "Telex" || e.englishMode {
+		t.Errorf("Delete the profile of the IDE, expected the global settings back, got %s english=%v", e.config.InputMethod, e.englishMode)
+	}
+}
+
+func TestConfigToSave(t *testing.T) {
+	var e = newAppProfileTestEngine()
+	focusApp(e, "code:Code")
+	e.config.IBflags &^= IBemojiDisabled
+	var config = e.configToSave()
+	if config == e.config || config.InputMethod != "Telex" || config.IBflags != IBstdFlags {
+		t.Errorf("Save the config in the IDE, expected a copy with the global settings, got %s %b", config.InputMethod, config.IBflags)
+	}
+	if e.config.InputMethod != "VNI" || e.config.IBflags&IBemojiDisabled != 0 {
+		t.Errorf("Save the config in the IDE, expected the settings in use unchanged, got %s %b", e.config.InputMethod, e.config.IBflags)
+	}
+	var profile = config.AppProfiles["code:Code"]
+	if profile.IBflags == nil || *profile.IBflags != e.config.IBflags || e.config.AppProfiles["code:Code"].IBflags != profile.IBflags {
+		t.Errorf("Save the config in the IDE, expected the profile to get the new flags, got %+v", profile)
+	}
+}
+
+func TestInputModeTableProfileRows(t *testing.T) {
+	var e = newAppProfileTestEngine()
+	focusApp(e, "code:Code")
+	e.config.PreeditWhiteList = []string{"code:Code"}
+	e.openLookupTable()
+	var lt = e.inputModeLookupTable
+	if lt.CursorPos != 0 {
+		t.Errorf("Open the input mode table in the IDE, expected the cursor on the pre-edit mode, got %d", lt.CursorPos)
+	}
+	for i, label := range lt.Labels {
+		if text := label.Value().(ibus.Text).Text; (text == "*") != (i == 0) {
+			t.Errorf("Open the input mode table in the IDE, expected only the pre-edit mode marked, got [%s] on row %d", text, i+1)
+		}
+	}
+	if text := lt.Candidates[7].Value().(ibus.Text).Text; !strings.HasPrefix(text, "Cập nhật") {
+		t.Errorf("Open the input mode table in the IDE, expected the profile to be updated, got [%s]", text)
+	}
+}
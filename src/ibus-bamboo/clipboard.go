@@ -0,0 +1,136 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"log"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// the X11 clipboard functions, replaced in the tests
+var (
+	getClipboard   = x11GetClipboard
+	copyClipboard  = x11Copy
+	resetClipboard = x11ClipboardReset
+)
+
+// clipboardHasUserText is set once the clipboard serves a text the user asked
+// for, a transformation which must stay until it is pasted, so that the mouse
+// moves do not empty it as they do with the commit buffer.
+var clipboardHasUserText int32
+
+// clipboardActions maps the property keys of the clipboard toolkit (which are
+// also the action names of Config.HotKeys) to their transformations.
+var clipboardActions = map[string]func(c *Config, text string) string{
+	PropKeyVnConvert: func(c *Config, text string) string {
+		return convertCharset(c.ClipboardInputCharset, c.ClipboardOutputCharset, text)
+	},
+	PropKeyClipboardRemoveTones: func(c *Config, text string) string {
+		return removeTones(text)
+	},
+	PropKeyClipboardRemoveMarks: func(c *Config, text string) string {
+		return removeMarks(text)
+	},
+	PropKeyClipboardUpperCase: func(c *Config, text string) string {
+		return strings.ToUpper(text)
+	},
+	PropKeyClipboardLowerCase: func(c *Config, text string) string {
+		return strings.ToLower(text)
+	},
+	PropKeyClipboardTitleCase: func(c *Config, text string) string {
+		return toTitleCase(text)
+	},
+	PropKeyClipboardNewToneStyle: func(c *Config, text string) string {
+		return bamboo.ConvertToneStyle(text, true)
+	},
+	PropKeyClipboardOldToneStyle: func(c *Config, text string) string {
+		return bamboo.ConvertToneStyle(text, false)
+	},
+}
+
+// transformClipboard replaces the content of the clipboard with its
+// transformation.
+func transformClipboard(c *Config, action string) {
+	var transform, found = clipboardActions[action]
+	if !found {
+		return
+	}
+	var text = getClipboard()
+	if text == "" {
+		log.Println("Nothing to transform in the clipboard")
+		return
+	}
+	atomic.StoreInt32(&clipboardHasUserText, 1)
+	copyClipboard(transform(c, text))
+}
+
+// resetCommitClipboard empties the clipboard buffer of the commits, a text the
+// user asked for is kept.
+func resetCommitClipboard() {
+	if atomic.LoadInt32(&clipboardHasUserText) == 0 {
+		resetClipboard()
+	}
+}
+
+func mapLowerCase(text string, mapping func(rune) rune) string {
+	return strings.Map(func(chr rune) rune {
+		var lower = unicode.ToLower(chr)
+		var result = mapping(lower)
+		if lower != chr {
+			return unicode.ToUpper(result)
+		}
+		return result
+	}, text)
+}
+
+func removeTones(text string) string {
+	return mapLowerCase(text, func(chr rune) rune {
+		return []rune(bamboo.RemoveToneFromWord(string(chr)))[0]
+	})
+}
+
+func removeMarks(text string) string {
+	return mapLowerCase(text, func(chr rune) rune {
+		return bamboo.RemoveMarkFromChar(bamboo.AddToneToChar(chr, 0))
+	})
+}
+
+// toTitleCase upper-cases the first letter of every word, the combining marks
+// of the decomposed forms (Unicode tổ hợp) belong to the word.
+func toTitleCase(text string) string {
+	var inWord = false
+	return strings.Map(func(chr rune) rune {
+		if unicode.Is(unicode.Mn, chr) {
+			return chr
+		}
+		if !unicode.IsLetter(chr) && !unicode.IsDigit(chr) {
+			inWord = false
+			return chr
+		}
+		if inWord {
+			return unicode.ToLower(chr)
+		}
+		inWord = true
+		return unicode.ToUpper(chr)
+	}, text)
+}
@@ -0,0 +1,65 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestClipboardActions(t *testing.T) {
+	var c = &Config{ClipboardInputCharset: "VIQR", ClipboardOutputCharset: "Unicode"}
+	var tests = []struct {
+		action   string
+		input    string
+		expected string
+	}{
+		{PropKeyVnConvert, "Vie^.t Nam", "Việt Nam"},
+		{PropKeyClipboardRemoveTones, "Tiếng Việt ĐẸP", "Tiêng Viêt ĐEP"},
+		{PropKeyClipboardRemoveMarks, "Tiếng Việt ĐẸP lắm ơi", "Tieng Viet DEP lam oi"},
+		{PropKeyClipboardUpperCase, "đường ưu", "ĐƯỜNG ƯU"},
+		{PropKeyClipboardLowerCase, "ĐƯỜNG ƯU", "đường ưu"},
+		{PropKeyClipboardTitleCase, "đường ưu-tiên ở HÀ NỘI", "Đường Ưu-Tiên Ở Hà Nội"},
+		{PropKeyClipboardTitleCase, "én ô", "Én Ô"},
+	}
+	for _, test := range tests {
+		if out := clipboardActions[test.action](c, test.input); out != test.expected {
+			t.Errorf("Transform [%s] by %s, expected [%s], got [%s]", test.input, test.action, test.expected, out)
+		}
+	}
+}
+
+func TestTransformedClipboardSurvivesMouseMove(t *testing.T) {
+	var clipboard = "Vie^.t Nam"
+	var oldGet, oldCopy, oldReset = getClipboard, copyClipboard, resetClipboard
+	getClipboard = func() string { return clipboard }
+	copyClipboard = func(text string) { clipboard = text }
+	resetClipboard = func() { clipboard = "" }
+	defer func() {
+		getClipboard, copyClipboard, resetClipboard = oldGet, oldCopy, oldReset
+		atomic.StoreInt32(&clipboardHasUserText, 0)
+	}()
+	var e = newTestEngine(IBstdFlags)
+	e.config.ClipboardInputCharset, e.config.ClipboardOutputCharset = "VIQR", "Unicode"
+	transformClipboard(e.config, PropKeyVnConvert)
+	e.mouseMoved()
+	if clipboard != "Việt Nam" {
+		t.Errorf("Move the mouse after converting the clipboard, expected [Việt Nam], got [%s]", clipboard)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io"
+	"os"
+)
+
+const CompileTrieCommand = "compile-trie"
+
+// compileDictionaryTrie builds the spelling trie of the dictionaries the way
+// bamboo.AddDictionaryToSpellingTrie does and lays it out in the compact format.
+func compileDictionaryTrie(dictionaryFiles ...string) ([]byte, error) {
+	var dictionary, err = loadDictionary(dictionaryFiles...)
+	if err != nil {
+		return nil, err
+	}
+	var trie = &bamboo.Node{}
+	for word := range dictionary {
+		bamboo.AddTrie(trie, []rune(word), true, false)
+	}
+	return bamboo.EncodeCompactTrie(trie)
+}
+
+// runCompileTrie implements `ibus-engine-bamboo compile-trie -o output
+// dictionaries...`, it is run at build time to precompile the system dictionary.
+func runCompileTrie(args []string, stderr io.Writer) error {
+	var flags = flag.NewFlagSet(CompileTrieCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var output = flags.String("o", DictVietnameseCmTrie, "The compact trie file to write")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [-o file] dictionaries...\n", os.Args[0], CompileTrieCommand)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("no dictionary to compile")
+	}
+	data, err := compileDictionaryTrie(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	// a running engine maps the old file, it keeps reading it when the new one
+	// is renamed over it, while truncating it in place would break its pages
+	return writeFileAtomically(*output, data)
+}
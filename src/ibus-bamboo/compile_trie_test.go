@@ -0,0 +1,70 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCompileTrie(t *testing.T) {
+	var dir = t.TempDir()
+	var dictFile = filepath.Join(dir, "words.dict")
+	var trieFile = filepath.Join(dir, "words.trie")
+	if err := ioutil.WriteFile(dictFile, []byte("Đắk\nlắk\n\nkrông\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	if err := runCompileTrie([]string{"-o", trieFile, dictFile}, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var trie, err = bamboo.OpenCompactTrie(trieFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trie.Close()
+	for _, word := range []string{"đắk", "Lắk", "krông"} {
+		if r := trie.TestString([]rune(word), true); r != bamboo.FindResultMatchFull {
+			t.Errorf("Compiled trie, expected [%s] in the dictionary, got [%d]", word, r)
+		}
+	}
+	// the mapped trie is not changed by compiling the file again
+	if err := ioutil.WriteFile(dictFile, []byte("buôn\nma\nthuột\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCompileTrie([]string{"-o", trieFile, dictFile}, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if r := trie.TestString([]rune("krông"), true); r != bamboo.FindResultMatchFull {
+		t.Errorf("Mapped trie after a new compilation, expected [krông] in the dictionary, got [%d]", r)
+	}
+	if info, err := os.Stat(trieFile); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0644 {
+		t.Errorf("Compiled trie file, expected the mode 0644, got %v", info.Mode())
+	}
+	if err := runCompileTrie([]string{"-o", trieFile}, &stderr); err == nil {
+		t.Errorf("Compile without a dictionary, expected an error")
+	}
+}
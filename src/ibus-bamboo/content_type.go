@@ -0,0 +1,86 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"log"
+)
+
+// The policies of Config.ContentTypePolicies, keyed by the names of the input
+// purposes below. A purpose which is not in the table is composed as usual.
+const (
+	ContentPolicyNormal  = "normal"
+	ContentPolicyPrivate = "private" // composed, but nothing is logged or learned
+	ContentPolicyBypass  = "bypass"  // not composed, nothing is logged or learned
+)
+
+var inputPurposeNames = map[uint32]string{
+	IBUS_INPUT_PURPOSE_FREE_FORM: "free_form",
+	IBUS_INPUT_PURPOSE_ALPHA:     "alpha",
+	IBUS_INPUT_PURPOSE_DIGITS:    "digits",
+	IBUS_INPUT_PURPOSE_NUMBER:    "number",
+	IBUS_INPUT_PURPOSE_PHONE:     "phone",
+	IBUS_INPUT_PURPOSE_URL:       "url",
+	IBUS_INPUT_PURPOSE_EMAIL:     "email",
+	IBUS_INPUT_PURPOSE_NAME:      "name",
+	IBUS_INPUT_PURPOSE_PASSWORD:  "password",
+	IBUS_INPUT_PURPOSE_PIN:       "pin",
+	IBUS_INPUT_PURPOSE_TERMINAL:  "terminal",
+}
+
+func getDefaultContentTypePolicies() map[string]string {
+	return map[string]string{
+		"password": ContentPolicyBypass,
+		"pin":      ContentPolicyBypass,
+		"email":    ContentPolicyBypass,
+		"url":      ContentPolicyBypass,
+		"number":   ContentPolicyBypass,
+		"digits":   ContentPolicyBypass,
+		"phone":    ContentPolicyBypass,
+	}
+}
+
+// getContentPolicy returns the policy of the focused field, a field which the
+// client marks as private is at least private.
+func (e *IBusBambooEngine) getContentPolicy() string {
+	var policy = e.config.ContentTypePolicies[inputPurposeNames[e.inputPurpose]]
+	if policy != ContentPolicyPrivate && policy != ContentPolicyBypass {
+		policy = ContentPolicyNormal
+	}
+	if policy == ContentPolicyNormal && e.inputHints&IBUS_INPUT_HINT_PRIVATE != 0 {
+		policy = ContentPolicyPrivate
+	}
+	return policy
+}
+
+func (e *IBusBambooEngine) isContentBypassed() bool {
+	return e.getContentPolicy() == ContentPolicyBypass
+}
+
+func (e *IBusBambooEngine) isContentPrivate() bool {
+	return e.getContentPolicy() != ContentPolicyNormal
+}
+
+// logText logs what the user types, unless the field is private.
+func (e *IBusBambooEngine) logText(v ...interface{}) {
+	if !e.isContentPrivate() {
+		log.Println(v...)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/BambooEngine/goibus/ibus"
+	"github.com/godbus/dbus"
+)
+
+func newContentTypeTestEngine() *IBusBambooEngine {
+	var e = newTestEngine(IBstdFlags)
+	e.config.ContentTypePolicies = getDefaultContentTypePolicies()
+	return e
+}
+
+func TestSetContentTypeBypass(t *testing.T) {
+	var purposes = map[uint32]string{
+		IBUS_INPUT_PURPOSE_PASSWORD: "passs",
+		IBUS_INPUT_PURPOSE_PIN:      "1234",
+		IBUS_INPUT_PURPOSE_EMAIL:    "ddoo@vieetj.vn",
+		IBUS_INPUT_PURPOSE_URL:      "https://vieetj.vn",
+		IBUS_INPUT_PURPOSE_NUMBER:   "3.14",
+		IBUS_INPUT_PURPOSE_PHONE:    "+84 912",
+	}
+	for purpose, text := range purposes {
+		var e = newContentTypeTestEngine()
+		e.SetContentType(purpose, IBUS_INPUT_HINT_NONE)
+		for _, chr := range []rune(text) {
+			if handled, _ := e.ProcessKeyEvent(uint32(chr), 0, 0); handled {
+				t.Errorf("Type [%s] in a %s field, expected [%c] to pass", text, inputPurposeNames[purpose], chr)
+				break
+			}
+		}
+		if e.getRawKeyLen() != 0 {
+			t.Errorf("Type [%s] in a %s field, expected no composition, got [%s]", text, inputPurposeNames[purpose], e.preeditor.GetRawString())
+		}
+	}
+}
+
+func TestSetContentTypeFreeForm(t *testing.T) {
+	var e = newContentTypeTestEngine()
+	e.SetContentType(IBUS_INPUT_PURPOSE_FREE_FORM, IBUS_INPUT_HINT_NONE)
+	typeString(e, "vieetj")
+	if e.getPreeditString() != "việt" {
+		t.Errorf("Type [vieetj] in a free form field, expected [việt], got [%s]", e.getPreeditString())
+	}
+	// the composition is dropped when a password field gets the focus
+	e.SetContentType(IBUS_INPUT_PURPOSE_PASSWORD, IBUS_INPUT_HINT_NONE)
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Switch to a password field, expected the composition to be dropped, got [%s]", e.preeditor.GetRawString())
+	}
+	e.SetContentType(IBUS_INPUT_PURPOSE_FREE_FORM, IBUS_INPUT_HINT_NONE)
+	typeString(e, "vieetj ")
+	if e.lastWord != "việt" {
+		t.Errorf("Type [vieetj] in a free form field, expected the last word [việt], got [%s]", e.lastWord)
+	}
+}
+
+func TestContentTypePolicies(t *testing.T) {
+	var e = newContentTypeTestEngine()
+	e.config.ContentTypePolicies["url"] = ContentPolicyNormal
+	e.SetContentType(IBUS_INPUT_PURPOSE_URL, IBUS_INPUT_HINT_NONE)
+	typeString(e, "vieetj")
+	if e.getPreeditString() != "việt" {
+		t.Errorf("Type [vieetj] in a url field with the normal policy, expected [việt], got [%s]", e.getPreeditString())
+	}
+	e.resetPreedit()
+
+	e.config.ContentTypePolicies["name"] = ContentPolicyPrivate
+	e.SetContentType(IBUS_INPUT_PURPOSE_NAME, IBUS_INPUT_HINT_NONE)
+	e.lastWord = ""
+	typeString(e, "vieetj ")
+	if e.lastWord != "" {
+		t.Errorf("Type [vieetj] in a private field, expected no last word, got [%s]", e.lastWord)
+	}
+
+	e.SetContentType(IBUS_INPUT_PURPOSE_FREE_FORM, IBUS_INPUT_HINT_PRIVATE)
+	if e.getContentPolicy() != ContentPolicyPrivate {
+		t.Errorf("Content policy of a private free form field, expected [%s], got [%s]", ContentPolicyPrivate, e.getContentPolicy())
+	}
+	e.config.LearnWordAfter = 1
+	e.learnRestoredWord("đắk")
+	if len(e.restoreCounts) != 0 {
+		t.Errorf("Learn a word in a private field, expected nothing to be counted, got %v", e.restoreCounts)
+	}
+}
+
+func TestSetSurroundingTextBypass(t *testing.T) {
+	var e = newContentTypeTestEngine()
+	e.config.SurroundingTextWhiteList = []string{e.wmClasses}
+	var text = dbus.MakeVariant([]interface{}{"IBusText", map[string]dbus.Variant{}, "việt", dbus.MakeVariant("")})
+	e.SetContentType(IBUS_INPUT_PURPOSE_PASSWORD, IBUS_INPUT_HINT_NONE)
+	e.SetSurroundingText(text, 4, 4)
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Set the surrounding text of a password field, expected no composition, got [%s]", e.preeditor.GetRawString())
+	}
+	e.SetContentType(IBUS_INPUT_PURPOSE_FREE_FORM, IBUS_INPUT_HINT_NONE)
+	e.SetSurroundingText(text, 4, 4)
+	if e.getRawKeyLen() == 0 {
+		t.Errorf("Set the surrounding text of a free form field, expected the text to be replayed")
+	}
+}
+
+func TestPasswordFieldAfterUserName(t *testing.T) {
+	var committed []string
+	var oldCommitToClient = commitToClient
+	commitToClient = func(e *IBusBambooEngine, text *ibus.Text) {
+		committed = append(committed, text.Text)
+	}
+	defer func() {
+		commitToClient = oldCommitToClient
+	}()
+	var e = newContentTypeTestEngine()
+	e.switchFocus("firefox:Firefox", 1)
+	e.SetContentType(IBUS_INPUT_PURPOSE_FREE_FORM, IBUS_INPUT_HINT_NONE)
+	typeString(e, "nguyeenx")
+	// the password field of the same window
+	e.switchFocus("firefox:Firefox", 1)
+	e.SetContentType(IBUS_INPUT_PURPOSE_PASSWORD, IBUS_INPUT_HINT_NONE)
+	typeString(e, "secret")
+	if len(committed) != 0 || e.getRawKeyLen() != 0 {
+		t.Errorf("Focus a password field after an unfinished user name, expected nothing committed, got %q", committed)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const ConvertCommand = "convert"
+
+func convertCharset(from, to, text string) string {
+	return bamboo.Encode(to, bamboo.Decode(from, text))
+}
+
+// runConvert implements `ibus-engine-bamboo convert [--from cs] [--to cs]
+// [--tone old|new] [files]`, it converts the files (or stdin) and writes the
+// result to stdout.
+func runConvert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var flags = flag.NewFlagSet(ConvertCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var from = flags.String("from", bamboo.UNICODE, "Charset of the input")
+	var to = flags.String("to", bamboo.UNICODE, "Charset of the output")
+	var tone = flags.String("tone", "", "Move the tones to the old (hoà) or the new (hòa) style")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [--from charset] [--to charset] [--tone old|new] [files...]\n", os.Args[0], ConvertCommand)
+		flags.PrintDefaults()
+		fmt.Fprintf(stderr, "Charsets: %s\n", strings.Join(sortStrings(bamboo.GetCharsetNames()), ", "))
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	for _, cs := range []string{*from, *to} {
+		if !isValidCharset(cs) {
+			flags.Usage()
+			return fmt.Errorf("unknown charset: %s", cs)
+		}
+	}
+	if *tone != "" && *tone != "old" && *tone != "new" {
+		flags.Usage()
+		return fmt.Errorf("unknown tone style: %s", *tone)
+	}
+	// the 8-bit charsets are read and written as raw bytes, not as UTF-8
+	var convert = func(data []byte) []byte {
+		var text = bamboo.DecodeBytes(*from, data)
+		if *tone != "" {
+			text = bamboo.ConvertToneStyle(text, *tone == "new")
+		}
+		return bamboo.EncodeBytes(*to, text)
+	}
+	if flags.NArg() == 0 {
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(convert(data))
+		return err
+	}
+	for _, fileName := range flags.Args() {
+		data, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		if _, err = stdout.Write(convert(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"bytes"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunConvertStdin(t *testing.T) {
+	var tcvn3 = bamboo.EncodeBytes("TCVN3 (ABC)", "tiếng việt")
+	var stdout, stderr bytes.Buffer
+	var err = runConvert([]string{"--from", "TCVN3 (ABC)", "--to", "Unicode"}, bytes.NewReader(tcvn3), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "tiếng việt" {
+		t.Errorf("Convert TCVN3 to Unicode, expected [tiếng việt], got [%s]", stdout.String())
+	}
+}
+
+func TestRunConvertRawBytes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var err = runConvert([]string{"--from", "TCVN3 (ABC)"}, strings.NewReader("Vi\xd6t"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "Việt" {
+		t.Errorf("Convert raw TCVN3 bytes, expected [Việt], got [%s]", stdout.String())
+	}
+	stdout.Reset()
+	if err = runConvert([]string{"--to", "TCVN3 (ABC)"}, strings.NewReader("Việt"), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stdout.Bytes(), []byte("Vi\xd6t")) {
+		t.Errorf("Convert to raw TCVN3 bytes, expected [% x], got [% x]", "Vi\xd6t", stdout.Bytes())
+	}
+}
+
+func TestRunConvertFiles(t *testing.T) {
+	var dir = t.TempDir()
+	var files = []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+	for i, text := range []string{"Việt ", "Nam"} {
+		if err := ioutil.WriteFile(files[i], []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	var err = runConvert(append([]string{"-to", "VIQR"}, files...), nil, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := bamboo.Encode("VIQR", "Việt Nam"); stdout.String() != expected {
+		t.Errorf("Convert files to VIQR, expected [%s], got [%s]", expected, stdout.String())
+	}
+}
+
+func TestRunConvertUnknownCharset(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := runConvert([]string{"--from", "ABC"}, strings.NewReader(""), &stdout, &stderr); err == nil {
+		t.Errorf("Convert from an unknown charset, expected an error")
+	}
+}
+
+func TestRunConvertToneStyle(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var err = runConvert([]string{"--tone", "new", "--to", "VIQR"}, strings.NewReader("hoà"), &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := bamboo.Encode("VIQR", "hòa"); stdout.String() != expected {
+		t.Errorf("Convert to the new tone style, expected [%s], got [%s]", expected, stdout.String())
+	}
+	if err = runConvert([]string{"--tone", "std"}, strings.NewReader(""), &stdout, &stderr); err == nil {
+		t.Errorf("Convert to an unknown tone style, expected an error")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io"
+	"os"
+	"strings"
+)
+
+const CountBigramsCommand = "count-bigrams"
+
+// countBigrams counts the word pairs of the corpus files, the maxPairs most
+// frequent ones are kept.
+func countBigrams(maxPairs int, corpusFiles ...string) (*bamboo.BigramModel, error) {
+	var readers []io.Reader
+	for _, corpusFile := range corpusFiles {
+		f, err := os.Open(corpusFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		// a file may not end with a new line, the next one must not go on its
+		// last sentence
+		readers = append(readers, f, strings.NewReader("\n"))
+	}
+	model, err := bamboo.CountBigrams(io.MultiReader(readers...))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err = model.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return bamboo.LoadBigramModel(&buf, maxPairs)
+}
+
+// runCountBigrams implements `ibus-engine-bamboo count-bigrams -o output
+// corpus...`, it builds the model of the next word prediction from plain text
+// files, see `make bigram`.
+func runCountBigrams(args []string, stderr io.Writer) error {
+	var flags = flag.NewFlagSet(CountBigramsCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var output = flags.String("o", DictVnBigrams, "The bigram model file to write")
+	var maxPairs = flags.Int("max-pairs", maxBigramPairs, "The number of word pairs kept, the most frequent ones")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s %s [-o file] [-max-pairs n] corpus...\n", os.Args[0], CountBigramsCommand)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("no corpus to count")
+	}
+	model, err := countBigrams(*maxPairs, flags.Args()...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %d word pairs counted by %s\n", model.Len(), CountBigramsCommand)
+	if _, err = model.WriteTo(&buf); err != nil {
+		return err
+	}
+	return writeFileAtomically(*output, buf.Bytes())
+}
@@ -0,0 +1,73 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func loadTestBigramModel(t *testing.T, modelFile string) *bamboo.BigramModel {
+	var f, err = os.Open(modelFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	model, err := bamboo.LoadBigramModel(f, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestRunCountBigrams(t *testing.T) {
+	var dir = t.TempDir()
+	var corpusFiles = []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}
+	var modelFile = filepath.Join(dir, "vietnamese.bigram")
+	if err := ioutil.WriteFile(corpusFiles[0], []byte("Cảm ơn bạn. Cảm ơn"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(corpusFiles[1], []byte("nhiều lắm.\nCảm thấy vui. Cảm ơn. Cảm thấy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	if err := runCountBigrams(append([]string{"-o", modelFile, "-max-pairs", "0"}, corpusFiles...), &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var model = loadTestBigramModel(t, modelFile)
+	if model.Len() != 5 || model.Count("ơn", "nhiều") != 0 {
+		t.Errorf("Count the bigrams of the corpus, expected 5 pairs and none across the files, got %d", model.Len())
+	}
+	if err := runCountBigrams(append([]string{"-o", modelFile, "-max-pairs", "2"}, corpusFiles...), &stderr); err != nil {
+		t.Fatal(err)
+	}
+	model = loadTestBigramModel(t, modelFile)
+	if model.Len() != 2 || !reflect.DeepEqual(model.Predict("cảm", 0), []string{"ơn", "thấy"}) {
+		t.Errorf("Count the bigrams of the corpus with a budget of 2 pairs, got %d and %q", model.Len(), model.Predict("cảm", 0))
+	}
+	if err := runCountBigrams([]string{"-o", modelFile}, &stderr); err == nil {
+		t.Errorf("Count without a corpus, expected an error")
+	}
+}
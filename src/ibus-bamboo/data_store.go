@@ -0,0 +1,355 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// how often the data files are checked for changes
+const dataReloadInterval = 3 * time.Second
+
+// how long the learned words wait to be written, so that a typing session
+// makes a single write
+const learnedSaveDelay = 30 * time.Second
+
+// storeData is a whole set of data loaded from the files.
+type storeData struct {
+	emojiMap         map[string]EmojiOne
+	userDictionary   map[string]bool
+	englishTrie      *bamboo.Node
+	wordDictionary   *bamboo.Dictionary
+	bigramModel      *bamboo.BigramModel
+	spellingProfiles map[string]bamboo.SpellingRules
+}
+
+// dataStore guards the data which is loaded in the background and read by the
+// engines: the spelling trie, the emoji, the user and English dictionaries, the
+// completion dictionary and the bigram model. The files are checked from time
+// to time and a whole new set of data is swapped in when one of them changes.
+type dataStore struct {
+	sync.RWMutex
+	engineName      string
+	dataDir         string
+	spellingProfile string
+	data            *storeData
+	emojiVersion    int
+	modTimes        map[string]time.Time
+	ready           chan struct{}
+	readyOnce       sync.Once
+	// loads are run one at a time
+	loadMutex sync.Mutex
+	// the learned words are read from their file on the first load and then
+	// kept in memory, they are written a while after a word is learned
+	learnedLoaded bool
+	learnedSave   *time.Timer
+}
+
+var store = newDataStore(strings.ToLower(EngineName), "")
+
+func newDataStore(engineName, dataDir string) *dataStore {
+	return &dataStore{
+		engineName:      engineName,
+		dataDir:         dataDir,
+		spellingProfile: DefaultSpellingProfile,
+		data: &storeData{
+			userDictionary:   map[string]bool{},
+			englishTrie:      &bamboo.Node{},
+			wordDictionary:   bamboo.NewDictionary(nil),
+			bigramModel:      bamboo.NewBigramModel(),
+			spellingProfiles: map[string]bamboo.SpellingRules{DefaultSpellingProfile: bamboo.DefaultSpellingRules},
+		},
+		ready: make(chan struct{}),
+	}
+}
+
+func (s *dataStore) systemFile(fileName string) string {
+	return filepath.Join(s.dataDir, fileName)
+}
+
+func (s *dataStore) files() []string {
+	return []string{
+		s.systemFile(DictVietnameseCm),
+		s.systemFile(DictVietnameseCmTrie),
+		s.systemFile(DictEmojiOne),
+		s.systemFile(DictVnPhrases),
+		s.systemFile(DictVnBigrams),
+		s.systemFile(DictEnglish),
+		s.systemFile(SpellingRulesFile),
+		getUserDictionaryFile(s.engineName),
+		getEnglishDictionaryFile(s.engineName),
+		getSpellingRulesFile(s.engineName),
+	}
+}
+
+func getModTimes(fileNames []string) map[string]time.Time {
+	var modTimes = map[string]time.Time{}
+	for _, fileName := range fileNames {
+		if info, err := os.Stat(fileName); err == nil {
+			modTimes[fileName] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+// isReady tells whether the data files have been loaded once.
+func (s *dataStore) isReady() bool {
+	select {
+	case <-s.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *dataStore) waitReady(timeout time.Duration) bool {
+	select {
+	case <-s.ready:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// load reads all the data files and swaps the new data in at once.
+func (s *dataStore) load() {
+	s.loadMutex.Lock()
+	defer s.loadMutex.Unlock()
+	var modTimes = getModTimes(s.files())
+	var data = &storeData{}
+	data.emojiMap, _ = loadEmojiOne(s.systemFile(DictEmojiOne))
+	data.spellingProfiles = mergeSpellingProfiles(s.systemFile(SpellingRulesFile), getSpellingRulesFile(s.engineName))
+	data.userDictionary = loadUserDictionary(s.engineName)
+	var englishWords, _ = loadDictionary(s.systemFile(DictEnglish))
+	var userEnglishWords, _ = loadDictionary(getEnglishDictionaryFile(s.engineName))
+	data.englishTrie = buildEnglishTrie(englishWords, userEnglishWords)
+	var phrases, _ = loadDictionary(s.systemFile(DictVnPhrases))
+	data.bigramModel = bamboo.NewBigramModel()
+	if model, err := loadBigramModel(s.systemFile(DictVnBigrams)); err == nil {
+		data.bigramModel = model
+	}
+
+	var spellingDictionaries = []map[string]bool{data.userDictionary}
+	var dictionaryTrie, err = s.openDictionaryTrie(modTimes)
+	if err != nil {
+		// the word list is only read when there is no usable compiled trie
+		log.Println(err)
+		var dictionary, _ = loadDictionary(s.systemFile(DictVietnameseCm))
+		spellingDictionaries = append(spellingDictionaries, dictionary)
+		data.wordDictionary = loadWordDictionary(nil, dictionary, phrases, data.userDictionary)
+	} else {
+		// the completions and the restorations are looked up in the spelling
+		// trie of the system dictionary
+		data.wordDictionary = loadWordDictionary(dictionaryTrie, phrases, data.userDictionary)
+	}
+	data.wordDictionary.SetBigramModel(data.bigramModel)
+	s.RLock()
+	var rules = getSpellingRules(data.spellingProfiles, s.spellingProfile)
+	var learnedLoaded = s.learnedLoaded
+	s.RUnlock()
+	if !learnedLoaded {
+		if f, err := os.Open(getLearnedWordsFile(s.engineName)); err == nil {
+			if err = data.wordDictionary.ReadCounts(f); err != nil {
+				log.Println("Failed to read the learned words:", err)
+			}
+			f.Close()
+		}
+	}
+	if err = bamboo.ReplaceSpellingData(rules, dictionaryTrie, spellingDictionaries...); err != nil {
+		log.Println(err)
+	}
+
+	s.Lock()
+	if learnedLoaded {
+		data.wordDictionary.LearnFrom(s.data.wordDictionary)
+	}
+	s.learnedLoaded = true
+	s.data = data
+	s.emojiVersion++
+	s.modTimes = modTimes
+	s.Unlock()
+	s.readyOnce.Do(func() {
+		close(s.ready)
+	})
+}
+
+// openDictionaryTrie maps the compiled system dictionary, unless it is older
+// than the word list it was compiled from.
+func (s *dataStore) openDictionaryTrie(modTimes map[string]time.Time) (*bamboo.CompactTrie, error) {
+	var trieFile, dictFile = s.systemFile(DictVietnameseCmTrie), s.systemFile(DictVietnameseCm)
+	var trieTime, hasTrie = modTimes[trieFile]
+	if dictTime, found := modTimes[dictFile]; found && hasTrie && trieTime.Before(dictTime) {
+		return nil, fmt.Errorf("%s is older than %s", trieFile, dictFile)
+	}
+	return bamboo.OpenCompactTrie(trieFile)
+}
+
+// reloadIfChanged loads the data again when a file was changed, added or
+// removed since the last load.
+func (s *dataStore) reloadIfChanged() bool {
+	var modTimes = getModTimes(s.files())
+	s.RLock()
+	var changed = len(modTimes) != len(s.modTimes)
+	for fileName, modTime := range modTimes {
+		if !s.modTimes[fileName].Equal(modTime) {
+			changed = true
+		}
+	}
+	s.RUnlock()
+	if changed {
+		s.load()
+	}
+	return changed
+}
+
+// watch loads the data and then reloads it whenever the files change.
+func (s *dataStore) watch(interval time.Duration) {
+	s.load()
+	for range time.Tick(interval) {
+		s.reloadIfChanged()
+	}
+}
+
+// touch records that the store itself wrote the file, so that it is not
+// reloaded for that.
+func (s *dataStore) touch(fileName string) {
+	if info, err := os.Stat(fileName); err == nil {
+		s.Lock()
+		if s.modTimes != nil {
+			s.modTimes[fileName] = info.ModTime()
+		}
+		s.Unlock()
+	}
+}
+
+// setSpellingProfile rebuilds the spelling trie with the rules of the profile.
+func (s *dataStore) setSpellingProfile(name string) {
+	s.loadMutex.Lock()
+	defer s.loadMutex.Unlock()
+	s.Lock()
+	s.spellingProfile = name
+	var rules = getSpellingRules(s.data.spellingProfiles, name)
+	s.Unlock()
+	if err := bamboo.SetSpellingRules(rules); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *dataStore) getSpellingProfileNames() []string {
+	s.RLock()
+	defer s.RUnlock()
+	var names []string
+	for name := range s.data.spellingProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *dataStore) getEmojiMap() (map[string]EmojiOne, int) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.emojiMap, s.emojiVersion
+}
+
+func (s *dataStore) isEnglishWord(word string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return bamboo.TestString(s.data.englishTrie, []rune(word), false) == bamboo.FindResultMatchFull
+}
+
+// addUserWord adds a word to the user dictionary and returns the new one, the
+// dictionary is copied as the spelling trie may be built from the old one.
+func (s *dataStore) addUserWord(word string) (map[string]bool, bool) {
+	s.loadMutex.Lock()
+	defer s.loadMutex.Unlock()
+	s.Lock()
+	defer s.Unlock()
+	if s.data.userDictionary[word] {
+		return nil, false
+	}
+	var words = make(map[string]bool, len(s.data.userDictionary)+1)
+	for w := range s.data.userDictionary {
+		words[w] = true
+	}
+	words[word] = true
+	s.data.userDictionary = words
+	bamboo.AddDictionaryToSpellingTrie(map[string]bool{word: true})
+	return words, true
+}
+
+func (s *dataStore) complete(typed string, limit int) []string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.wordDictionary.Complete(typed, limit)
+}
+
+func (s *dataStore) containsWord(word string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.wordDictionary.Contains(word)
+}
+
+func (s *dataStore) hasAccentlessPrefix(text string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.wordDictionary.HasAccentlessPrefix(text)
+}
+
+func (s *dataStore) restore(text, previous string, limit int) []string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.wordDictionary.Restore(text, previous, limit)
+}
+
+func (s *dataStore) learnPhrase(phrase string) {
+	s.Lock()
+	defer s.Unlock()
+	s.data.wordDictionary.Learn(phrase)
+	if s.learnedSave == nil {
+		s.learnedSave = time.AfterFunc(learnedSaveDelay, s.saveLearnedWords)
+	}
+}
+
+func (s *dataStore) saveLearnedWords() {
+	var buf bytes.Buffer
+	s.Lock()
+	s.learnedSave = nil
+	s.data.wordDictionary.WriteCounts(&buf)
+	s.Unlock()
+	if err := writeFileAtomically(getLearnedWordsFile(s.engineName), buf.Bytes()); err != nil {
+		log.Println("Failed to save the learned words:", err)
+	}
+}
+
+func (s *dataStore) predict(word string, limit int) []string {
+	s.RLock()
+	defer s.RUnlock()
+	return s.data.bigramModel.Predict(word, limit)
+}
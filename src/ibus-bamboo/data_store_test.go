@@ -0,0 +1,184 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// withReadyStore replaces the data store with an empty one which is ready
+// until the test ends.
+func withReadyStore(t *testing.T) {
+	var saved = store
+	store = newDataStore("bamboo", "")
+	store.readyOnce.Do(func() {
+		close(store.ready)
+	})
+	t.Cleanup(func() {
+		store = saved
+	})
+}
+
+func writeDataFile(t *testing.T, dir, fileName, content string) {
+	var path = filepath.Join(dir, fileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// withTestDataStore replaces the data store with one which reads its files
+// from a temporary directory until the test ends.
+func withTestDataStore(t *testing.T) string {
+	var dir = t.TempDir()
+	writeDataFile(t, dir, DictVietnameseCm, "việt\nnam\n")
+	writeDataFile(t, dir, DictVnPhrases, "việt nam\n")
+	writeDataFile(t, dir, DictVnBigrams, "việt\tnam 3\n")
+	writeDataFile(t, dir, DictEnglish, "test\n")
+	writeDataFile(t, dir, DictEmojiOne, `{"1f602": {"name": "face with tears of joy", "shortname": ":joy:", "ascii": [":')"]}}`)
+	withUserDictionaryFile(t)
+	withLearnedWordsFile(t)
+	var saved = store
+	store = newDataStore("bamboo", dir)
+	t.Cleanup(func() {
+		store = saved
+		bamboo.ReplaceSpellingData(bamboo.DefaultSpellingRules, nil)
+	})
+	return dir
+}
+
+func TestDataStoreLoad(t *testing.T) {
+	withTestDataStore(t)
+	var e = newTestEngine(IBstdFlags | IBspellCheckingWithDicts | IBwordCompletionEnabled)
+	if store.isReady() || e.isDictionarySpellingEnabled() {
+		t.Fatalf("Data store before the first load, expected it not to be ready")
+	}
+	// the engine keeps reading while the data is loaded
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		store.load()
+	}()
+	for !store.isReady() {
+		typeString(e, "vieej ")
+	}
+	wg.Wait()
+	if !e.isDictionarySpellingEnabled() {
+		t.Errorf("Data store after the first load, expected the dictionary spelling to be enabled")
+	}
+	if words := store.complete("việ", 0); len(words) != 2 {
+		t.Errorf("Complete [việ] after the load, got %q", words)
+	}
+	if words := store.predict("việt", 1); len(words) != 1 || words[0] != "nam" {
+		t.Errorf("Predict after [việt], got %q", words)
+	}
+	if !isEnglishWord("Test") {
+		t.Errorf("English word [Test] after the load, expected [true], got [false]")
+	}
+	if data, version := store.getEmojiMap(); len(data) != 1 || version != 1 {
+		t.Errorf("Emoji data after the load, expected 1 emoji of version 1, got %d of version %d", len(data), version)
+	}
+	typeString(e, "vieetj")
+	if e.getComposedString() != "việt" {
+		t.Errorf("Process [vieetj] with the dictionary, expected [việt], got [%s]", e.getComposedString())
+	}
+}
+
+func TestDataStoreCompiledTrie(t *testing.T) {
+	var dir = withTestDataStore(t)
+	writeDataFile(t, dir, "compiled.dict", "việc\nviệt\n")
+	var trieFile = filepath.Join(dir, DictVietnameseCmTrie)
+	var stderr bytes.Buffer
+	if err := runCompileTrie([]string{"-o", trieFile, filepath.Join(dir, "compiled.dict")}, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var later = time.Now().Add(time.Minute)
+	os.Chtimes(trieFile, later, later)
+	store.load()
+	// the words come from the trie, the word list is not read
+	if words := store.complete("việ", 0); len(words) != 3 || store.containsWord("nam") {
+		t.Errorf("Complete [việ] with the compiled trie, got %q", words)
+	}
+	var newer = later.Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, DictVietnameseCm), newer, newer)
+	store.load()
+	if !store.containsWord("nam") || store.containsWord("việc") {
+		t.Errorf("Load a word list newer than its trie, expected the list to be read")
+	}
+}
+
+func TestDataStoreReload(t *testing.T) {
+	var dir = withTestDataStore(t)
+	store.load()
+	if store.reloadIfChanged() {
+		t.Errorf("Reload unchanged files, expected no reload")
+	}
+	store.learnPhrase("việt nam")
+	writeDataFile(t, dir, DictVietnameseCm, "việt\nnam\nviệc\n")
+	var later = time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(dir, DictVietnameseCm), later, later)
+	var e = newTestEngine(IBstdFlags | IBwordCompletionEnabled)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if !store.reloadIfChanged() {
+			t.Errorf("Reload a changed dictionary, expected a reload")
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		typeString(e, "vieej ")
+	}
+	wg.Wait()
+	if words := store.complete("việ", 0); len(words) != 3 {
+		t.Errorf("Complete [việ] after the reload, got %q", words)
+	}
+	var learned bytes.Buffer
+	store.data.wordDictionary.WriteCounts(&learned)
+	if learned.String() != "việt nam\t1\n" {
+		t.Errorf("Learned words after the reload, expected them to be kept, got [%s]", learned.String())
+	}
+	if _, version := store.getEmojiMap(); version != 2 {
+		t.Errorf("Emoji data after the reload, expected version 2, got %d", version)
+	}
+
+	// the store does not reload the user dictionary it saved itself
+	if err := addToUserDictionary("bamboo", "krông"); err != nil {
+		t.Fatal(err)
+	}
+	if store.reloadIfChanged() {
+		t.Errorf("Reload after adding a user word, expected no reload")
+	}
+	var e2 = newTestEngine(IBstdFlags | IBspellCheckingWithDicts)
+	typeString(e2, "kroong")
+	if e2.getComposedString() != "krông" {
+		t.Errorf("Process [kroong] after adding it to the user dictionary, expected [krông], got [%s]", e2.getComposedString())
+	}
+}
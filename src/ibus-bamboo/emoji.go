@@ -34,8 +34,6 @@ type EmojiOne struct {
 	Ascii     []string
 }
 
-var emojiMap map[string]EmojiOne
-
 func loadEmojiOne(dataFile string) (map[string]EmojiOne, error) {
 	var c = map[string]EmojiOne{}
 	if data, err := ioutil.ReadFile(dataFile); err == nil {
@@ -52,13 +50,12 @@ type EmojiEngine struct {
 	keys           []rune
 }
 
-func NewEmojiEngine() *EmojiEngine {
+func NewEmojiEngine(data map[string]EmojiOne) *EmojiEngine {
 	var be = &EmojiEngine{
 		shortNameTable: map[string]string{},
 		asciiTable:     map[string]string{},
 		emojiTrie:      &bamboo.Node{},
 	}
-	var data = emojiMap
 	for k, v := range data {
 		var codePointStr string
 		for _, codePoint := range strings.Split(k, "-") {
@@ -41,8 +41,8 @@ func TestLoadEmojiData(t *testing.T) {
 }
 
 func TestEmojiFindResult(t *testing.T) {
-	emojiMap, _ = loadEmojiOne("../../" + DictEmojiOne)
-	var be = NewEmojiEngine()
+	var data, _ = loadEmojiOne("../../" + DictEmojiOne)
+	var be = NewEmojiEngine(data)
 	if be.TestString(":'") != bamboo.FindResultMatchPrefix {
 		t.Errorf("Finding result for emoji :', expected %d, got %d", bamboo.FindResultMatchPrefix, be.TestString(":'"))
 	}
@@ -58,8 +58,8 @@ func TestEmojiFindResult(t *testing.T) {
 }
 
 func TestFilterEmoji(t *testing.T) {
-	emojiMap, _ = loadEmojiOne("../../" + DictEmojiOne)
-	var be = NewEmojiEngine()
+	var data, _ = loadEmojiOne("../../" + DictEmojiOne)
+	var be = NewEmojiEngine(data)
 	var grinnings = be.Filter(":')")
 	if !inStringList(grinnings, "😂") {
 		t.Errorf("Filtering emojo :'), expected %v, got %v", true, inStringList(grinnings, "😂"))
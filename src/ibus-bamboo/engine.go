@@ -37,10 +37,13 @@ type IBusBambooEngine struct {
 	engineName           string
 	config               *Config
 	propList             *ibus.PropList
+	hotKeyBindings       []hotKeyBinding
 	ignorePreedit        bool
 	englishMode          bool
+	englishModes         *englishModeState
 	macroTable           *MacroTable
 	wmClasses            string
+	focusWindow          uint32
 	isInputModeLTOpened  bool
 	isEmojiLTOpened      bool
 	emojiLookupTable     *ibus.LookupTable
@@ -50,9 +53,30 @@ type IBusBambooEngine struct {
 	firstTimeSendingBS   bool
 	isFocusOut           bool
 	emoji                *EmojiEngine
+	emojiVersion         int
 	lastKeyWithShift     bool
 	shiftRightIsPressing bool
 	nFakeShiftLeft       int
+	autoCommitTimer      autoCommitTimer
+	autoCommitSeq        int
+	isIMErrorShown       bool
+	imErrorSeq           int
+	isCandidateLTOpened  bool
+	candidateLookupTable *ibus.LookupTable
+	isSwitcherLTOpened   bool
+	switcherLookupTable  *ibus.LookupTable
+	switcherItems        []switcherItem
+	candidates           []string
+	onSelectCandidate    func(string)
+	isPredictionLT       bool
+	restoreText          string
+	lastRestoredWord     string
+	lastWord             string
+	restoreCounts        map[string]int
+	appProfile           *AppProfile
+	globalSettings       AppProfile
+	inputPurpose         uint32
+	inputHints           uint32
 }
 
 /**
@@ -71,26 +95,24 @@ Return:
 This function gets called whenever a key is pressed.
 */
 func (e *IBusBambooEngine) ProcessKeyEvent(keyVal uint32, keyCode uint32, state uint32) (bool, *dbus.Error) {
+	// the auto-commit timer may touch the preeditor from another goroutine
+	e.Lock()
+	defer e.Unlock()
+	if e.isContentBypassed() {
+		// passwords, addresses and numbers go to the client as typed
+		return false, nil
+	}
 	if e.processShiftKey(keyVal, state) {
 		return true, nil
 	}
 	if e.isIgnoredKey(keyVal, state) {
 		return false, nil
 	}
-	log.Printf("keyCode 0x%04x keyval 0x%04x | %c | %d\n", keyCode, keyVal, rune(keyVal), len(keyPressChan))
-	if e.config.IBflags&IBinputModeLookupTableEnabled != 0 && keyVal == IBUS_OpenLookupTable && e.isInputModeLTOpened == false && e.wmClasses != "" {
-		e.resetBuffer()
-		e.isInputModeLTOpened = true
-		e.openLookupTable()
-		e.lastKeyWithShift = true
+	if action := e.getHotKeyAction(keyVal, state); action != "" && e.runHotKeyAction(action) {
 		return true, nil
 	}
-	if e.config.IBflags&IBemojiDisabled == 0 && keyVal == IBUS_Colon && e.isEmojiLTOpened == false {
-		e.resetBuffer()
-		e.isEmojiLTOpened = true
-		e.openEmojiList()
-		e.lastKeyWithShift = true
-		return true, nil
+	if !e.isContentPrivate() {
+		log.Printf("keyCode 0x%04x keyval 0x%04x | %c | %d\n", keyCode, keyVal, rune(keyVal), len(keyPressChan))
 	}
 	if e.isInputModeLTOpened {
 		return e.ltProcessKeyEvent(keyVal, keyCode, state)
@@ -98,10 +120,27 @@ func (e *IBusBambooEngine) ProcessKeyEvent(keyVal uint32, keyCode uint32, state
 	if e.isEmojiLTOpened {
 		return e.emojiProcessKeyEvent(keyVal, keyCode, state)
 	}
+	if e.isSwitcherLTOpened && e.switcherProcessKeyEvent(keyVal, state) {
+		return true, nil
+	}
 	if e.englishMode {
 		e.updateLastKeyWithShift(keyVal, state)
 		return false, nil
 	}
+	if e.config.IBflags&IBkeyCodeLayoutEnabled != 0 {
+		var qwertyKeyVal, ok = e.getQwertyKeyVal(keyVal, keyCode, state)
+		if !ok {
+			// a key which has no role in the input method keeps the character of the layout
+			e.resetBuffer()
+			e.updateLastKeyWithShift(keyVal, state)
+			return false, nil
+		}
+		keyVal = qwertyKeyVal
+	}
+	keyVal = e.getModifiedKeyVal(keyVal, keyCode, state)
+	if e.config.IBflags&IBdiacriticRestorationEnabled != 0 {
+		return e.restoreProcessKeyEvent(keyVal, keyCode, state)
+	}
 	if e.inPreeditList() {
 		return e.preeditProcessKeyEvent(keyVal, keyCode, state)
 	}
@@ -113,26 +152,43 @@ func (e *IBusBambooEngine) ProcessKeyEvent(keyVal uint32, keyCode uint32, state
 
 func (e *IBusBambooEngine) FocusIn() *dbus.Error {
 	log.Print("FocusIn.")
-	var oldWmClasses = e.wmClasses
-	e.wmClasses = x11GetFocusWindowClass()
-	fmt.Printf("WM_CLASS=(%s)\n", e.wmClasses)
-
+	e.Lock()
+	defer e.Unlock()
+	var window uint32
+	if e.config.IBflags&IBenglishModePerWindow != 0 {
+		window = x11GetFocusWindow()
+	}
+	e.switchFocus(x11GetFocusWindowClass(), window)
 	e.RegisterProperties(e.propList)
 	e.RequireSurroundingText()
 	e.isFocusOut = false
+
+	return nil
+}
+
+func (e *IBusBambooEngine) switchFocus(wmClasses string, window uint32) {
+	var oldWmClasses, oldWindow = e.wmClasses, e.focusWindow
+	e.wmClasses, e.focusWindow = wmClasses, window
+	fmt.Printf("WM_CLASS=(%s)\n", e.wmClasses)
+
 	if oldWmClasses != e.wmClasses {
 		e.firstTimeSendingBS = true
 		e.resetBuffer()
 		e.resetFakeBackspace()
+		e.applyAppProfile()
 		// x11ClipboardReset()
 	}
-
-	return nil
+	if oldWmClasses != e.wmClasses || oldWindow != e.focusWindow {
+		e.restoreEnglishMode()
+	}
 }
 
 func (e *IBusBambooEngine) FocusOut() *dbus.Error {
 	log.Print("FocusOut.")
+	e.Lock()
 	e.isFocusOut = true
+	e.stopAutoCommit()
+	e.Unlock()
 	//e.wmClasses = ""
 	return nil
 }
@@ -157,7 +213,10 @@ func (e *IBusBambooEngine) Disable() *dbus.Error {
 
 //@method(in_signature="vuu")
 func (e *IBusBambooEngine) SetSurroundingText(text dbus.Variant, cursorPos uint32, anchorPos uint32) *dbus.Error {
-	if e.getRawKeyLen() > 0 {
+	e.Lock()
+	defer e.Unlock()
+	if e.getRawKeyLen() > 0 || e.isContentBypassed() {
+		// a bypassed field is never composed, its text is not even read
 		return nil
 	}
 	defer func() {
@@ -171,7 +230,7 @@ func (e *IBusBambooEngine) SetSurroundingText(text dbus.Variant, cursorPos uint3
 		if len(s) < int(cursorPos) {
 			return nil
 		}
-		fmt.Println("Surrounding Text: ", string(s[:cursorPos]))
+		e.logText("Surrounding Text: ", string(s[:cursorPos]))
 		e.preeditor.Reset()
 		e.preeditor.ProcessString(string(s[:cursorPos]), bamboo.EnglishMode)
 	}
@@ -179,46 +238,96 @@ func (e *IBusBambooEngine) SetSurroundingText(text dbus.Variant, cursorPos uint3
 }
 
 func (e *IBusBambooEngine) PageUp() *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
+	e.pageUp()
+	return nil
+}
+
+func (e *IBusBambooEngine) pageUp() {
 	if e.isEmojiLTOpened && e.emojiLookupTable.PageUp() {
 		e.updateEmojiLookupTable()
 	}
 	if e.isInputModeLTOpened && e.inputModeLookupTable.PageUp() {
 		e.updateInputModeLT()
 	}
-	return nil
+	if e.isCandidateLTOpened && e.candidateLookupTable.PageUp() {
+		e.updateCandidateLT()
+	}
+	if e.isSwitcherLTOpened && e.switcherLookupTable.PageUp() {
+		e.updateSwitcherLT()
+	}
 }
 
 func (e *IBusBambooEngine) PageDown() *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
+	e.pageDown()
+	return nil
+}
+
+func (e *IBusBambooEngine) pageDown() {
 	if e.isEmojiLTOpened && e.emojiLookupTable.PageDown() {
 		e.updateEmojiLookupTable()
 	}
 	if e.isInputModeLTOpened && e.inputModeLookupTable.PageDown() {
 		e.updateInputModeLT()
 	}
-	return nil
+	if e.isCandidateLTOpened && e.candidateLookupTable.PageDown() {
+		e.updateCandidateLT()
+	}
+	if e.isSwitcherLTOpened && e.switcherLookupTable.PageDown() {
+		e.updateSwitcherLT()
+	}
 }
 
 func (e *IBusBambooEngine) CursorUp() *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
+	e.cursorUp()
+	return nil
+}
+
+func (e *IBusBambooEngine) cursorUp() {
 	if e.isEmojiLTOpened && e.emojiLookupTable.CursorUp() {
 		e.updateEmojiLookupTable()
 	}
 	if e.isInputModeLTOpened && e.inputModeLookupTable.CursorUp() {
 		e.updateInputModeLT()
 	}
-	return nil
+	if e.isCandidateLTOpened && e.candidateLookupTable.CursorUp() {
+		e.updateCandidateLT()
+	}
+	if e.isSwitcherLTOpened && e.switcherLookupTable.CursorUp() {
+		e.updateSwitcherLT()
+	}
 }
 
 func (e *IBusBambooEngine) CursorDown() *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
+	e.cursorDown()
+	return nil
+}
+
+func (e *IBusBambooEngine) cursorDown() {
 	if e.isEmojiLTOpened && e.emojiLookupTable.CursorDown() {
 		e.updateEmojiLookupTable()
 	}
 	if e.isInputModeLTOpened && e.inputModeLookupTable.CursorDown() {
 		e.updateInputModeLT()
 	}
-	return nil
+	if e.isCandidateLTOpened && e.candidateLookupTable.CursorDown() {
+		e.updateCandidateLT()
+	}
+	if e.isSwitcherLTOpened && e.switcherLookupTable.CursorDown() {
+		e.updateSwitcherLT()
+	}
 }
 
 func (e *IBusBambooEngine) CandidateClicked(index uint32, button uint32, state uint32) *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
 	if e.isEmojiLTOpened && e.emojiLookupTable.SetCursorPosInCurrentPage(index) {
 		e.commitEmojiCandidate()
 		e.closeEmojiCandidates()
@@ -227,6 +336,12 @@ func (e *IBusBambooEngine) CandidateClicked(index uint32, button uint32, state u
 		e.commitInputModeCandidate()
 		e.closeInputModeCandidates()
 	}
+	if e.isCandidateLTOpened && e.candidateLookupTable.SetCursorPosInCurrentPage(index) {
+		e.selectCandidate()
+	}
+	if e.isSwitcherLTOpened && e.switcherLookupTable.SetCursorPosInCurrentPage(index) {
+		e.commitSwitcherCandidate()
+	}
 	return nil
 }
 
@@ -240,6 +355,14 @@ func (e *IBusBambooEngine) SetCursorLocation(x int32, y int32, w int32, h int32)
 }
 
 func (e *IBusBambooEngine) SetContentType(purpose uint32, hints uint32) *dbus.Error {
+	e.Lock()
+	defer e.Unlock()
+	e.inputPurpose, e.inputHints = purpose, hints
+	if e.isContentBypassed() {
+		// the word left unfinished in the previous field, e.g. the user name,
+		// must not be committed into this one
+		e.resetPreedit()
+	}
 	return nil
 }
 
@@ -249,18 +372,36 @@ func (e *IBusBambooEngine) PropertyActivate(propName string, propState uint32) *
 		exec.Command("xdg-open", HomePage).Start()
 		return nil
 	}
-	if propName == PropKeyVnConvert {
-		exec.Command("xdg-open", CharsetConvertPage).Start()
-		return nil
-	}
 	if propName == PropKeyBambooConfiguration {
-		exec.Command("xdg-open", getConfigPath(e.engineName)).Start()
+		OpenInputMethodFile(e.engineName)
 		return nil
 	}
 	if propName == PropKeyMacroTable {
 		OpenMactabFile(e.engineName)
 		return nil
 	}
+	if propName == PropKeyUserDictionary {
+		OpenUserDictionaryFile(e.engineName)
+		return nil
+	}
+	if propName == PropKeyEnglishDictionaryFile {
+		OpenEnglishDictionaryFile(e.engineName)
+		return nil
+	}
+
+	e.Lock()
+	defer e.Unlock()
+	if _, found := clipboardActions[propName]; found {
+		// the goroutine gets a copy, e.config may change while it waits for the clipboard
+		var config = *e.config
+		go transformClipboard(&config, propName)
+		return nil
+	}
+	if propName == PropKeyAddToUserDictionary {
+		e.addLastWordToUserDictionary()
+		return nil
+	}
+	var oldInputMethod, oldFlags = e.config.InputMethod, e.config.Flags
 
 	turnSpellChecking := func(on bool) {
 		if on {
@@ -299,6 +440,13 @@ func (e *IBusBambooEngine) PropertyActivate(propName string, propState uint32) *
 			e.config.Flags &= ^bamboo.EfreeToneMarking
 		}
 	}
+	if propName == PropKeyQuickTelex {
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.Flags |= bamboo.EquickTelex
+		} else {
+			e.config.Flags &= ^bamboo.EquickTelex
+		}
+	}
 	if propName == PropKeySpellingChecking {
 		if propState == ibus.PROP_STATE_CHECKED {
 			turnSpellChecking(true)
@@ -407,6 +555,50 @@ func (e *IBusBambooEngine) PropertyActivate(propName string, propState uint32) *
 		}
 		e.englishMode = false
 	}
+	if propName == PropKeyDiacriticRestoration {
+		e.resetBuffer()
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBdiacriticRestorationEnabled
+		} else {
+			e.config.IBflags &= ^IBdiacriticRestorationEnabled
+		}
+	}
+	if propName == PropKeyEnglishDictionary {
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBenglishDictEnabled
+		} else {
+			e.config.IBflags &= ^IBenglishDictEnabled
+		}
+	}
+	if propName == PropKeyEnglishModePerWindow {
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBenglishModePerWindow
+		} else {
+			e.config.IBflags &= ^IBenglishModePerWindow
+		}
+	}
+	if propName == PropKeyKeyCodeLayout {
+		e.resetBuffer()
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBkeyCodeLayoutEnabled
+		} else {
+			e.config.IBflags &= ^IBkeyCodeLayoutEnabled
+		}
+	}
+	if propName == PropKeyNextWordPrediction {
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBnextWordPredictionEnabled
+		} else {
+			e.config.IBflags &= ^IBnextWordPredictionEnabled
+		}
+	}
+	if propName == PropKeyWordCompletion {
+		if propState == ibus.PROP_STATE_CHECKED {
+			e.config.IBflags |= IBwordCompletionEnabled
+		} else {
+			e.config.IBflags &= ^IBwordCompletionEnabled
+		}
+	}
 	if propName == PropKeyAutoCapitalizeMacro {
 		if propState == ibus.PROP_STATE_CHECKED {
 			e.config.IBflags |= IBautoCapitalizeMacro
@@ -422,11 +614,17 @@ func (e *IBusBambooEngine) PropertyActivate(propName string, propState uint32) *
 	if _, found := e.config.InputMethodDefinitions[propName]; found && propState == ibus.PROP_STATE_CHECKED {
 		e.config.InputMethod = propName
 	}
-	SaveConfig(e.config, e.engineName)
+	if profile, found := getSpellingProfileFromPropKey(propName); found && propState == ibus.PROP_STATE_CHECKED {
+		if profile != e.config.SpellingProfile {
+			e.config.SpellingProfile = profile
+			store.setSpellingProfile(profile)
+		}
+	}
+	e.saveConfig()
 	e.propList = GetPropListByConfig(e.config)
 
-	var inputMethod = bamboo.ParseInputMethod(e.config.InputMethodDefinitions, e.config.InputMethod)
-	e.preeditor = bamboo.NewEngine(inputMethod, e.config.Flags)
 	e.RegisterProperties(e.propList)
+
+	e.updatePreeditor(oldInputMethod, oldFlags)
 	return nil
 }
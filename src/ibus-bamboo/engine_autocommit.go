@@ -0,0 +1,120 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type autoCommitTimer interface {
+	Stop() bool
+}
+
+// autoCommitAfterFunc is replaced by a fake clock in tests
+var autoCommitAfterFunc = func(d time.Duration, f func()) autoCommitTimer {
+	return time.AfterFunc(d, f)
+}
+
+// scheduleAutoCommit (re)starts the idle timer which commits the pre-edit
+// text after AutoCommitAfter milliseconds without any key stroke.
+func (e *IBusBambooEngine) scheduleAutoCommit() {
+	e.stopAutoCommit()
+	if e.config.IBflags&IBautoCommitWithDelay == 0 || e.config.AutoCommitAfter <= 0 || e.getRawKeyLen() == 0 {
+		return
+	}
+	var seq = e.autoCommitSeq
+	e.autoCommitTimer = autoCommitAfterFunc(time.Duration(e.config.AutoCommitAfter)*time.Millisecond, func() {
+		e.Lock()
+		defer e.Unlock()
+		// a key stroke came in while this callback was waiting for the lock
+		if seq != e.autoCommitSeq {
+			return
+		}
+		e.autoCommitTimer = nil
+		e.commitPreedit()
+	})
+}
+
+func (e *IBusBambooEngine) stopAutoCommit() {
+	e.autoCommitSeq++
+	if e.autoCommitTimer != nil {
+		e.autoCommitTimer.Stop()
+		e.autoCommitTimer = nil
+	}
+}
+
+// A word is complete when its spelling is fully matched, it already carries
+// a tone and no more letters can be appended to it, e.g. "việt", "chuyển".
+func (e *IBusBambooEngine) isCompleteWord() bool {
+	var vnSeq = e.getProcessedString(bamboo.VietnameseMode | bamboo.LowerCase)
+	if !hasTone(vnSeq) {
+		return false
+	}
+	if e.getSpellingMatchResult(e.isDictionarySpellingEnabled()) != bamboo.FindResultMatchFull {
+		return false
+	}
+	return !bamboo.CanExtendSpelling(e.getProcessedString(bamboo.VietnameseMode | bamboo.ToneLess | bamboo.LowerCase))
+}
+
+func (e *IBusBambooEngine) shouldAutoCommitWithFullMatch() bool {
+	if e.config.IBflags&IBautoCommitWithVnFullMatch == 0 || e.getRawKeyLen() == 0 {
+		return false
+	}
+	return e.isCompleteWord()
+}
+
+func (e *IBusBambooEngine) shouldAutoCommitWithNotMatch() bool {
+	if e.config.IBflags&IBautoCommitWithVnNotMatch == 0 || e.getRawKeyLen() == 0 {
+		return false
+	}
+	var vnSeq = e.getProcessedString(bamboo.VietnameseMode | bamboo.LowerCase)
+	if e.config.IBflags&IBmarcoEnabled != 0 && e.macroTable.HasKey(vnSeq) {
+		return false
+	}
+	return e.getSpellingMatchResult(false) == bamboo.FindResultNotMatch
+}
+
+// shouldAutoCommitWithWordBreak tells whether the key starts a new word: the
+// current word is fully matched, the key is a plain letter (not an effect key
+// of the input method) and the word can not be extended, e.g. "việt" + n.
+func (e *IBusBambooEngine) shouldAutoCommitWithWordBreak(keyRune rune) bool {
+	if e.config.IBflags&IBautoCommitWithVnWordBreak == 0 || e.getRawKeyLen() == 0 {
+		return false
+	}
+	if !bamboo.IsAlpha(keyRune) || strings.ContainsRune(string(e.preeditor.GetInputMethod().Keys), unicode.ToLower(keyRune)) {
+		return false
+	}
+	if e.getSpellingMatchResult(false) != bamboo.FindResultMatchFull {
+		return false
+	}
+	return !bamboo.CanExtendSpelling(e.getProcessedString(bamboo.VietnameseMode | bamboo.ToneLess | bamboo.LowerCase))
+}
+
+func hasTone(str string) bool {
+	for _, chr := range []rune(str) {
+		if bamboo.FindToneFromChar(chr) != bamboo.TONE_NONE {
+			return true
+		}
+	}
+	return false
+}
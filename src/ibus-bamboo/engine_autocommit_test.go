@@ -0,0 +1,147 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeTimer struct {
+	at      time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	var active = !t.stopped
+	t.stopped = true
+	return active
+}
+
+type fakeClock struct {
+	now    time.Duration
+	timers []*fakeTimer
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) autoCommitTimer {
+	var t = &fakeTimer{at: c.now + d, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now += d
+	for _, t := range c.timers {
+		if !t.stopped && t.at <= c.now {
+			t.stopped = true
+			t.f()
+		}
+	}
+}
+
+func useFakeClock(t *testing.T) *fakeClock {
+	var clock = &fakeClock{}
+	var afterFunc = autoCommitAfterFunc
+	autoCommitAfterFunc = clock.AfterFunc
+	t.Cleanup(func() {
+		autoCommitAfterFunc = afterFunc
+	})
+	return clock
+}
+
+func TestAutoCommitWithDelay(t *testing.T) {
+	var clock = useFakeClock(t)
+	var e = newTestEngine(IBstdFlags | IBautoCommitWithDelay)
+	e.config.AutoCommitAfter = 1000
+	typeString(e, "ban")
+	clock.Advance(900 * time.Millisecond)
+	typeString(e, "g")
+	clock.Advance(900 * time.Millisecond)
+	if e.getPreeditString() != "bang" {
+		t.Errorf("Auto commit before the delay, expected [bang], got [%s]", e.getPreeditString())
+	}
+	clock.Advance(100 * time.Millisecond)
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Auto commit after the delay, expected an empty composition, got [%s]", e.preeditor.GetRawString())
+	}
+}
+
+func TestAutoCommitWithDelayDisabled(t *testing.T) {
+	var clock = useFakeClock(t)
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "ban")
+	clock.Advance(time.Hour)
+	if e.getPreeditString() != "ban" {
+		t.Errorf("Auto commit without the flag, expected [ban], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestAutoCommitWithVnFullMatch(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBautoCommitWithVnFullMatch)
+	typeString(e, "vieet")
+	if e.getPreeditString() != "viêt" {
+		t.Errorf("Process [vieet], expected [viêt], got [%s]", e.getPreeditString())
+	}
+	typeString(e, "j")
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Auto commit [vieetj], expected an empty composition, got [%s]", e.preeditor.GetRawString())
+	}
+	typeString(e, "tins")
+	if e.getPreeditString() != "tín" {
+		t.Errorf("Process [tins], expected [tín], got [%s]", e.getPreeditString())
+	}
+	typeString(e, "h")
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Auto commit [tinsh], expected an empty composition, got [%s]", e.preeditor.GetRawString())
+	}
+}
+
+func TestAutoCommitWithVnWordBreak(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBautoCommitWithVnWordBreak)
+	typeString(e, "vieetjn")
+	if e.preeditor.GetRawString() != "n" {
+		t.Errorf("Auto commit [vieetjn], expected raw string [n], got [%s]", e.preeditor.GetRawString())
+	}
+	e.resetPreedit()
+	typeString(e, "tin")
+	typeString(e, "h")
+	if e.getPreeditString() != "tinh" {
+		t.Errorf("Process [tinh], expected [tinh], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestAutoCommitWithVnNotMatch(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBautoCommitWithVnNotMatch)
+	typeString(e, "than")
+	if e.getPreeditString() != "than" {
+		t.Errorf("Process [than], expected [than], got [%s]", e.getPreeditString())
+	}
+	typeString(e, "k")
+	if e.getRawKeyLen() != 0 || !e.ignorePreedit {
+		t.Errorf("Auto commit [thank], expected an empty composition, got [%s]", e.preeditor.GetRawString())
+	}
+	if ret, _ := e.ProcessKeyEvent('s', 0, 0); ret {
+		t.Errorf("Process [s] after an auto commit, expected the key to be forwarded")
+	}
+	typeString(e, " ")
+	if e.ignorePreedit {
+		t.Errorf("Process a word break after an auto commit, expected to process keys again")
+	}
+}
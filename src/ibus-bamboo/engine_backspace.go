@@ -100,20 +100,34 @@ func (e *IBusBambooEngine) keyPressHandler(keyVal, keyCode, state uint32) {
 		return
 	}
 
-	if e.preeditor.CanProcessKey(keyRune) {
+	if e.preeditor.CanProcessKeyWithModifiers(keyRune, getModifiers(state)) {
 		if state&IBUS_LOCK_MASK != 0 {
 			keyRune = toUpper(keyRune)
 		}
+		if e.shouldAutoCommitWithWordBreak(keyRune) {
+			e.preeditor.Reset()
+		}
 		oldRunes := []rune(e.getPreeditString())
-		e.preeditor.ProcessKey(keyRune, e.getMode())
+		e.preeditor.ProcessKeyWithModifiers(keyRune, getModifiers(state), e.getMode())
 		newRunes := []rune(e.getPreeditString())
 		e.updatePreviousText(newRunes, oldRunes, state)
+		if e.shouldAutoCommitWithFullMatch() {
+			// the text is already in the client, just forget the composition
+			e.preeditor.Reset()
+		}
 		return
 	} else if bamboo.IsWordBreakSymbol(keyRune) {
-		if keyVal == IBUS_Space && state&IBUS_SHIFT_MASK != 0 &&
-			e.config.IBflags&IBrestoreKeyStrokesEnabled != 0 && !e.lastKeyWithShift {
+		if e.isRestoreKey(keyVal, state) {
 			// restore key strokes
 			var vnSeq = e.getPreeditString()
+			if e.mustFallbackToEnglish() && e.isSpellingCorrect() {
+				// keep the word which the dictionary check would revert
+				e.learnRestoredWord(vnSeq)
+				e.lastWord = vnSeq
+				e.preeditor.ProcessKey(keyRune, bamboo.EnglishMode)
+				e.SendText([]rune{keyRune})
+				return
+			}
 			if bamboo.HasVietnameseChar(vnSeq) {
 				e.preeditor.RestoreLastWord()
 				newRunes := []rune(e.getPreeditString())
@@ -169,7 +183,7 @@ func (e *IBusBambooEngine) updatePreviousText(newRunes, oldRunes []rune, state u
 		}
 	}
 	diffFrom := sameTo + 1
-	log.Println("Updating Previous Text", string(oldRunes), string(newRunes), diffFrom)
+	e.logText("Updating Previous Text", string(oldRunes), string(newRunes), diffFrom)
 
 	nBackSpace := 0
 	// workaround for chrome and firefox's address bar
@@ -278,7 +292,7 @@ func (e *IBusBambooEngine) SendText(rs []rune) {
 		return
 	}
 	if e.inDirectForwardKeyList() {
-		log.Println("Forward as commit", string(rs))
+		e.logText("Forward as commit", string(rs))
 		for _, chr := range rs {
 			var keyVal = vnSymMapping[chr]
 			if keyVal == 0 {
@@ -290,6 +304,6 @@ func (e *IBusBambooEngine) SendText(rs []rune) {
 		}
 		return
 	}
-	log.Println("Sending text", string(rs))
+	e.logText("Sending text", string(rs))
 	e.commitText(string(rs))
 }
@@ -0,0 +1,138 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/goibus/ibus"
+	"strings"
+	"unicode"
+)
+
+// The word candidates (completions...) share one lookup table, the chosen
+// candidate is handed to onSelectCandidate.
+func (e *IBusBambooEngine) openCandidates(candidates []string, onSelect func(string)) {
+	if len(candidates) == 0 {
+		e.closeCandidates()
+		return
+	}
+	lt := ibus.NewLookupTable()
+	lt.Orientation = IBUS_ORIENTATION_HORIZONTAL
+	for _, candidate := range candidates {
+		lt.AppendCandidate(candidate)
+	}
+	e.candidates = candidates
+	e.candidateLookupTable = lt
+	e.onSelectCandidate = onSelect
+	e.isCandidateLTOpened = true
+	e.updateCandidateLT()
+}
+
+func (e *IBusBambooEngine) updateCandidateLT() {
+	e.UpdateLookupTable(e.candidateLookupTable, true)
+}
+
+func (e *IBusBambooEngine) closeCandidates() {
+	if !e.isCandidateLTOpened {
+		return
+	}
+	e.candidates = nil
+	e.candidateLookupTable = nil
+	e.onSelectCandidate = nil
+	e.isCandidateLTOpened = false
+	e.isPredictionLT = false
+	e.HideLookupTable()
+}
+
+func (e *IBusBambooEngine) selectCandidate() {
+	var pos = e.candidateLookupTable.CursorPos
+	if pos >= uint32(len(e.candidates)) {
+		return
+	}
+	var candidate, onSelect = e.candidates[pos], e.onSelectCandidate
+	e.closeCandidates()
+	onSelect(candidate)
+}
+
+// number keys select a candidate while composing unless the input method uses
+// them (VNI...), the predictions shown after a word are chosen with Tab
+func (e *IBusBambooEngine) canSelectCandidateByNumber(keyRune rune) bool {
+	if keyRune < '1' || keyRune > '9' || e.getRawKeyLen() == 0 {
+		return false
+	}
+	return !strings.ContainsRune(string(e.preeditor.GetInputMethod().Keys), keyRune)
+}
+
+// candidateProcessKeyEvent handles the keys which move in or select from the
+// candidate lookup table, the other keys are left to the caller.
+func (e *IBusBambooEngine) candidateProcessKeyEvent(keyVal uint32, state uint32) bool {
+	if e.isPredictionLT {
+		// the predictions pop up after every word, only Tab picks one and
+		// the other keys go on as if they were not shown
+		if keyVal == IBUS_Tab && state&(IBUS_CONTROL_MASK|IBUS_MOD1_MASK|IBUS_SHIFT_MASK) == 0 {
+			e.selectCandidate()
+			return true
+		}
+		e.closeCandidates()
+		return false
+	}
+	if state&(IBUS_CONTROL_MASK|IBUS_MOD1_MASK) != 0 {
+		return false
+	}
+	var keyRune = rune(keyVal)
+	switch {
+	case keyVal == IBUS_Tab:
+		e.selectCandidate()
+	case keyVal == IBUS_Up:
+		e.cursorUp()
+	case keyVal == IBUS_Down:
+		e.cursorDown()
+	case keyVal == IBUS_Page_Up:
+		e.pageUp()
+	case keyVal == IBUS_Page_Down:
+		e.pageDown()
+	case keyVal == IBUS_Escape:
+		e.closeCandidates()
+	case e.canSelectCandidateByNumber(keyRune):
+		if !e.candidateLookupTable.SetCursorPosInCurrentPage(uint32(keyRune - '1')) {
+			return false
+		}
+		e.selectCandidate()
+	default:
+		return false
+	}
+	return true
+}
+
+// matchCase gives the candidate the case of what the user typed, e.g.
+// "Việ" -> "Việt nam", "VIỆ" -> "VIỆT NAM".
+func matchCase(candidate, typed string) string {
+	var chars = []rune(typed)
+	if len(chars) == 0 || strings.ToLower(typed) == typed {
+		return candidate
+	}
+	if len(chars) > 1 && strings.ToUpper(typed) == typed {
+		return strings.ToUpper(candidate)
+	}
+	var candidateChars = []rune(candidate)
+	if len(candidateChars) == 0 || !unicode.IsUpper(chars[0]) {
+		return candidate
+	}
+	return string(unicode.ToUpper(candidateChars[0])) + string(candidateChars[1:])
+}
@@ -0,0 +1,66 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+)
+
+const maxCompletions = 20
+
+// loadWordDictionary makes the completion dictionary of the system trie, which
+// may be nil, and of the other dictionaries.
+func loadWordDictionary(trie *bamboo.CompactTrie, dictionaries ...map[string]bool) *bamboo.Dictionary {
+	var list []string
+	for _, dictionary := range dictionaries {
+		for word := range dictionary {
+			list = append(list, word)
+		}
+	}
+	return bamboo.NewDictionary(list, trie)
+}
+
+// updateCompletions lists the dictionary words and phrases which start with
+// the pre-edit text.
+func (e *IBusBambooEngine) updateCompletions() {
+	if e.config.IBflags&IBwordCompletionEnabled == 0 {
+		// the candidates of the previous composition, if any, are stale
+		e.closeCandidates()
+		return
+	}
+	var typed = e.getPreeditString()
+	var completions = store.complete(typed, maxCompletions)
+	for i, completion := range completions {
+		completions[i] = matchCase(completion, typed)
+	}
+	e.openCandidates(completions, e.commitCompletion)
+}
+
+func (e *IBusBambooEngine) commitCompletion(completion string) {
+	e.commitText(completion)
+	e.resetPreedit()
+}
+
+func (e *IBusBambooEngine) toggleWordCompletion() {
+	e.config.IBflags ^= IBwordCompletionEnabled
+	if e.config.IBflags&IBwordCompletionEnabled == 0 {
+		e.closeCandidates()
+	}
+}
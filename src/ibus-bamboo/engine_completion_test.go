@@ -0,0 +1,104 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"testing"
+)
+
+func withWordDictionary(t *testing.T, words []string) {
+	var saved = store.data.wordDictionary
+	store.data.wordDictionary = bamboo.NewDictionary(words)
+	t.Cleanup(func() {
+		store.data.wordDictionary = saved
+	})
+}
+
+func TestWordCompletion(t *testing.T) {
+	withWordDictionary(t, []string{"việt", "việt nam", "viết", "vì"})
+	var e = newTestEngine(IBstdFlags | IBwordCompletionEnabled)
+	typeString(e, "vieej")
+	if !e.isCandidateLTOpened {
+		t.Fatalf("Process [vieej], expected the candidates to be shown")
+	}
+	if len(e.candidates) != 2 || e.candidates[0] != "việt" || e.candidates[1] != "việt nam" {
+		t.Errorf("Complete [việ], got %v", e.candidates)
+	}
+	e.ProcessKeyEvent(IBUS_Down, 0, 0)
+	if e.candidateLookupTable.CursorPos != 1 {
+		t.Errorf("Move down, expected cursor at 1, got %d", e.candidateLookupTable.CursorPos)
+	}
+	e.ProcessKeyEvent(IBUS_Tab, 0, 0)
+	if e.isCandidateLTOpened || e.getRawKeyLen() != 0 {
+		t.Errorf("Select with Tab, expected the composition to be committed, got [%s]", e.getPreeditString())
+	}
+
+	typeString(e, "vieej2")
+	if e.isCandidateLTOpened || e.getRawKeyLen() != 0 {
+		t.Errorf("Select with 2, expected the composition to be committed, got [%s]", e.getPreeditString())
+	}
+
+	typeString(e, "vieej")
+	e.ProcessKeyEvent(IBUS_Escape, 0, 0)
+	if e.isCandidateLTOpened || e.getPreeditString() != "việ" {
+		t.Errorf("Close with Escape, expected [việ] to stay, got [%s]", e.getPreeditString())
+	}
+}
+
+func TestWordCompletionDisabled(t *testing.T) {
+	withWordDictionary(t, []string{"việt"})
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "vieej")
+	if e.isCandidateLTOpened {
+		t.Errorf("Completion is disabled, expected no candidates, got %v", e.candidates)
+	}
+}
+
+func TestCandidateNumberKeysWithVni(t *testing.T) {
+	withWordDictionary(t, []string{"viên"})
+	var e = newTestEngine(IBstdFlags | IBwordCompletionEnabled)
+	e.config.InputMethod = "VNI"
+	e.preeditor = bamboo.NewEngine(bamboo.ParseInputMethod(e.config.InputMethodDefinitions, "VNI"), e.config.Flags)
+	typeString(e, "vie6")
+	if !e.isCandidateLTOpened {
+		t.Fatalf("Process [vie6], expected the candidates to be shown")
+	}
+	typeString(e, "5")
+	if e.getPreeditString() != "việ" {
+		t.Errorf("Process [vie65], expected [việ], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestMatchCase(t *testing.T) {
+	var tests = []struct {
+		candidate, typed, expected string
+	}{
+		{"việt nam", "việ", "việt nam"},
+		{"việt nam", "Việ", "Việt nam"},
+		{"việt nam", "VIỆ", "VIỆT NAM"},
+		{"việt nam", "V", "Việt nam"},
+	}
+	for _, test := range tests {
+		if got := matchCase(test.candidate, test.typed); got != test.expected {
+			t.Errorf("matchCase(%s, %s), expected [%s], got [%s]", test.candidate, test.typed, test.expected, got)
+		}
+	}
+}
@@ -26,7 +26,23 @@ import (
 	"strconv"
 )
 
+func (e *IBusBambooEngine) openEmojiTable() bool {
+	if e.config.IBflags&IBemojiDisabled != 0 || e.isEmojiLTOpened {
+		return false
+	}
+	e.resetBuffer()
+	e.isEmojiLTOpened = true
+	e.openEmojiList()
+	e.lastKeyWithShift = true
+	return true
+}
+
 func (e *IBusBambooEngine) openEmojiList() {
+	// the emoji data is loaded in the background and may have been reloaded
+	if data, version := store.getEmojiMap(); version != e.emojiVersion {
+		e.emoji = NewEmojiEngine(data)
+		e.emojiVersion = version
+	}
 	e.emoji.ProcessKey(':')
 	e.UpdatePreeditText(ibus.NewText(":"), 1, true)
 	e.UpdateAuxiliaryText(ibus.NewText(":"), true)
@@ -45,7 +61,7 @@ func (e *IBusBambooEngine) emojiProcessKeyEvent(keyVal uint32, keyCode uint32, s
 	var keyRune = rune(keyVal)
 	var cps = e.emoji.Query()
 	var reset = e.closeEmojiCandidates
-	if keyVal == IBUS_Colon {
+	if e.getHotKeyAction(keyVal, state) == HotKeyOpenEmoji {
 		reset()
 		return false, nil
 	}
@@ -70,16 +86,16 @@ func (e *IBusBambooEngine) emojiProcessKeyEvent(keyVal uint32, keyCode uint32, s
 		return false, nil
 	}
 	if keyVal == IBUS_Left || keyVal == IBUS_Up {
-		e.CursorUp()
+		e.cursorUp()
 		return true, nil
 	} else if keyVal == IBUS_Right || keyVal == IBUS_Down {
-		e.CursorDown()
+		e.cursorDown()
 		return true, nil
 	} else if keyVal == IBUS_Page_Up {
-		e.PageUp()
+		e.pageUp()
 		return true, nil
 	} else if keyVal == IBUS_Page_Down {
-		e.PageDown()
+		e.pageDown()
 		return true, nil
 	}
 	if keyVal == IBUS_BackSpace {
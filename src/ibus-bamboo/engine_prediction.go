@@ -0,0 +1,61 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"os"
+	"strings"
+)
+
+const (
+	maxPredictions = 9
+	// the number of word pairs kept in memory, about 8 bytes each plus the
+	// vocabulary
+	maxBigramPairs = 400000
+)
+
+func loadBigramModel(dataFile string) (*bamboo.BigramModel, error) {
+	f, err := os.Open(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bamboo.LoadBigramModel(f, maxBigramPairs)
+}
+
+// openPredictions lists the words which are likely to follow the committed
+// word, e.g. "cảm" -> "ơn".
+func (e *IBusBambooEngine) openPredictions(committed string) {
+	if e.config.IBflags&IBnextWordPredictionEnabled == 0 {
+		return
+	}
+	var words = strings.Fields(committed)
+	if len(words) == 0 {
+		return
+	}
+	e.openCandidates(store.predict(words[len(words)-1], maxPredictions), e.commitPrediction)
+	e.isPredictionLT = e.isCandidateLTOpened
+}
+
+func (e *IBusBambooEngine) commitPrediction(word string) {
+	e.commitText(word + " ")
+	e.openPredictions(word)
+}
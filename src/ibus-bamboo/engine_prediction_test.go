@@ -0,0 +1,78 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"strings"
+	"testing"
+)
+
+func withBigramModel(t *testing.T, model string) {
+	var saved = store.data.bigramModel
+	var m, err = bamboo.LoadBigramModel(strings.NewReader(model), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.data.bigramModel = m
+	t.Cleanup(func() {
+		store.data.bigramModel = saved
+	})
+}
+
+func TestNextWordPrediction(t *testing.T) {
+	withBigramModel(t, "cảm\tơn 10\tthấy 3\nơn\tbạn 5\n")
+	var e = newTestEngine(IBstdFlags | IBnextWordPredictionEnabled)
+	typeString(e, "camr ")
+	if !e.isCandidateLTOpened || len(e.candidates) != 2 || e.candidates[0] != "ơn" {
+		t.Fatalf("Commit [cảm] with a space, expected the predictions, got %v", e.candidates)
+	}
+	e.ProcessKeyEvent(IBUS_Tab, 0, 0)
+	if !e.isCandidateLTOpened || len(e.candidates) != 1 || e.candidates[0] != "bạn" {
+		t.Errorf("Select [ơn], expected the predictions of [ơn], got %v", e.candidates)
+	}
+	typeString(e, "1")
+	if e.isCandidateLTOpened {
+		t.Errorf("Process [1] after a prediction, expected the digit to close the predictions")
+	}
+
+	typeString(e, "camr ")
+	typeString(e, "t")
+	if e.isCandidateLTOpened || e.getPreeditString() != "t" {
+		t.Errorf("Process [t] after the predictions, expected them to be closed, got [%s]", e.getPreeditString())
+	}
+	// the keys which move in a lookup table are not taken by the predictions
+	for _, keyVal := range []uint32{IBUS_Up, IBUS_Down, IBUS_Page_Up, IBUS_Page_Down, IBUS_Escape} {
+		e.resetBuffer()
+		typeString(e, "camr ")
+		if ret, _ := e.ProcessKeyEvent(keyVal, 0, 0); ret || e.isCandidateLTOpened {
+			t.Errorf("Process 0x%x after the predictions, expected it to go to the client and close them", keyVal)
+		}
+	}
+}
+
+func TestNextWordPredictionDisabled(t *testing.T) {
+	withBigramModel(t, "cảm\tơn 10\n")
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "camr ")
+	if e.isCandidateLTOpened {
+		t.Errorf("Prediction is disabled, expected no candidates, got %v", e.candidates)
+	}
+}
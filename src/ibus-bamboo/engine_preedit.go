@@ -23,7 +23,6 @@ import (
 	"github.com/BambooEngine/bamboo-core"
 	"github.com/BambooEngine/goibus/ibus"
 	"github.com/godbus/dbus"
-	"log"
 	"strings"
 )
 
@@ -32,6 +31,9 @@ func (e *IBusBambooEngine) preeditProcessKeyEvent(keyVal uint32, keyCode uint32,
 	var rawKeyLen = e.getRawKeyLen()
 	var keyRune = rune(keyVal)
 
+	if e.isCandidateLTOpened && e.candidateProcessKeyEvent(keyVal, state) {
+		return true, nil
+	}
 	if !e.isValidState(state) {
 		e.commitPreedit()
 		return false, nil
@@ -41,28 +43,52 @@ func (e *IBusBambooEngine) preeditProcessKeyEvent(keyVal uint32, keyCode uint32,
 		if rawKeyLen > 0 {
 			e.preeditor.RemoveLastChar()
 			e.updatePreedit(e.getPreeditString())
+			e.updateCompletions()
+			e.scheduleAutoCommit()
 			return true, nil
 		} else {
+			e.closeCandidates()
 			return false, nil
 		}
 	}
-	if e.preeditor.CanProcessKey(keyRune) {
+	if e.preeditor.CanProcessKeyWithModifiers(keyRune, getModifiers(state)) {
 		if state&IBUS_LOCK_MASK != 0 {
 			keyRune = toUpper(keyRune)
 		}
 		if e.ignorePreedit {
 			return false, nil
 		}
-		e.preeditor.ProcessKey(keyRune, e.getMode())
+		if e.shouldAutoCommitWithWordBreak(keyRune) {
+			e.commitPreedit()
+		}
+		e.preeditor.ProcessKeyWithModifiers(keyRune, getModifiers(state), e.getMode())
+		if e.shouldAutoCommitWithNotMatch() {
+			// the rest of this word goes straight to the client
+			e.commitText(e.getPreeditString())
+			e.resetPreedit()
+			e.ignorePreedit = true
+			return true, nil
+		}
+		if e.shouldAutoCommitWithFullMatch() {
+			e.commitPreedit()
+			return true, nil
+		}
 		e.updatePreedit(e.getPreeditString())
+		e.updateCompletions()
+		e.scheduleAutoCommit()
 		return true, nil
 	} else if bamboo.IsWordBreakSymbol(keyRune) {
+		e.stopAutoCommit()
 		e.ignorePreedit = false
-		if keyVal == IBUS_Space && state&IBUS_SHIFT_MASK != 0 &&
-			e.config.IBflags&IBrestoreKeyStrokesEnabled != 0 && !e.lastKeyWithShift {
+		if e.isRestoreKey(keyVal, state) {
 			// restore key strokes
 			var vnSeq = e.preeditor.GetProcessedString(bamboo.VietnameseMode)
-			if bamboo.HasVietnameseChar(vnSeq) {
+			if e.mustFallbackToEnglish() && e.isSpellingCorrect() {
+				// keep the word which the dictionary check would revert
+				e.learnRestoredWord(vnSeq)
+				e.commitText(vnSeq + string(keyRune))
+				e.resetPreedit()
+			} else if bamboo.HasVietnameseChar(vnSeq) {
 				e.preeditor.RestoreLastWord()
 				e.updatePreedit(e.getPreeditString())
 			} else {
@@ -78,8 +104,12 @@ func (e *IBusBambooEngine) preeditProcessKeyEvent(keyVal uint32, keyCode uint32,
 			e.resetPreedit()
 			return true, nil
 		}
-		e.commitText(e.getComposedString() + string(keyRune))
+		var composedStr = e.getComposedString()
+		e.commitText(composedStr + string(keyRune))
 		e.resetPreedit()
+		if keyVal == IBUS_Space {
+			e.openPredictions(composedStr)
+		}
 		return true, nil
 	}
 	e.commitPreedit()
@@ -126,6 +156,9 @@ func (e *IBusBambooEngine) updatePreedit(processedStr string) {
 }
 
 func (e *IBusBambooEngine) shouldFallbackToEnglish() bool {
+	if e.shouldKeepEnglish() {
+		return true
+	}
 	if e.config.IBflags&IBautoNonVnRestore == 0 {
 		return false
 	}
@@ -158,6 +191,9 @@ func (e *IBusBambooEngine) hasVietnameseChar() bool {
 }
 
 func (e *IBusBambooEngine) mustFallbackToEnglish() bool {
+	if e.shouldKeepEnglish() {
+		return true
+	}
 	if e.config.IBflags&IBautoNonVnRestore == 0 {
 		return false
 	}
@@ -178,12 +214,18 @@ func (e *IBusBambooEngine) mustFallbackToEnglish() bool {
 	if e.preeditor.GetSpellingMatchResult(bamboo.LowerCase, true) == bamboo.FindResultMatchFull {
 		return false
 	}
-	if e.config.IBflags&IBspellCheckingWithDicts == 0 {
+	if !e.isDictionarySpellingEnabled() {
 		return e.getSpellingMatchResult(false) != bamboo.FindResultMatchFull
 	}
 	return true
 }
 
+// the dictionaries are loaded in the background, the spelling is checked by
+// the rules only until they are ready
+func (e *IBusBambooEngine) isDictionarySpellingEnabled() bool {
+	return e.config.IBflags&IBspellCheckingWithDicts != 0 && store.isReady()
+}
+
 func (e *IBusBambooEngine) isSpellingCorrect() bool {
 	return e.getSpellingMatchResult(false) == bamboo.FindResultMatchFull
 }
@@ -239,11 +281,18 @@ func (e *IBusBambooEngine) getMode() bamboo.Mode {
 }
 
 func (e *IBusBambooEngine) resetPreedit() {
+	e.stopAutoCommit()
+	e.closeCandidates()
 	e.HidePreeditText()
+	if vnSeq := e.getProcessedString(bamboo.VietnameseMode); vnSeq != "" && !e.isContentPrivate() {
+		e.lastWord = vnSeq
+	}
 	e.preeditor.Reset()
+	e.restoreText = ""
 }
 
 func (e *IBusBambooEngine) commitPreedit() {
+	e.closeCandidates()
 	e.HidePreeditText()
 	if e.getRawKeyLen() == 0 {
 		return
@@ -252,9 +301,14 @@ func (e *IBusBambooEngine) commitPreedit() {
 	e.resetPreedit()
 }
 
+// commitToClient sends the text to the application, replaced in the tests.
+var commitToClient = func(e *IBusBambooEngine, text *ibus.Text) {
+	e.CommitText(text)
+}
+
 func (e *IBusBambooEngine) commitText(str string) {
-	log.Println("Commit Text", str)
-	e.CommitText(ibus.NewText(e.encodeText(str)))
+	e.logText("Commit Text", str)
+	commitToClient(e, ibus.NewText(e.encodeText(str)))
 }
 
 func (e *IBusBambooEngine) getVnSeq() string {
@@ -0,0 +1,138 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"github.com/godbus/dbus"
+	"strings"
+)
+
+// getLearnedWordsFile is replaced in tests
+var getLearnedWordsFile = func(engineName string) string {
+	return fmt.Sprintf(learnedDictFile, getConfigDir(), engineName)
+}
+
+// restoreProcessKeyEvent handles the keys in the diacritic restoration mode:
+// plain ASCII letters are kept in the pre-edit and the accented words and
+// phrases which read the same are offered in the candidate lookup table.
+func (e *IBusBambooEngine) restoreProcessKeyEvent(keyVal uint32, keyCode uint32, state uint32) (bool, *dbus.Error) {
+	defer e.updateLastKeyWithShift(keyVal, state)
+	var keyRune = rune(keyVal)
+
+	if e.isCandidateLTOpened && e.candidateProcessKeyEvent(keyVal, state) {
+		return true, nil
+	}
+	if !e.isValidState(state) {
+		e.commitRestoreText()
+		return false, nil
+	}
+	if keyVal == IBUS_BackSpace {
+		if e.restoreText == "" {
+			return false, nil
+		}
+		var chars = []rune(e.restoreText)
+		e.restoreText = string(chars[:len(chars)-1])
+		e.updateRestoreText()
+		return true, nil
+	}
+	if bamboo.IsAlpha(keyRune) {
+		if state&IBUS_LOCK_MASK != 0 {
+			keyRune = toUpper(keyRune)
+		}
+		e.restoreText += string(keyRune)
+		e.updateRestoreText()
+		return true, nil
+	}
+	if e.restoreText != "" && bamboo.IsWordBreakSymbol(keyRune) {
+		// keep on typing a phrase of the dictionary, e.g. "viet nam"
+		if keyVal == IBUS_Space && !strings.HasSuffix(e.restoreText, " ") &&
+			store.hasAccentlessPrefix(e.restoreText+" ") {
+			e.restoreText += " "
+			e.updateRestoreText()
+			return true, nil
+		}
+		var restored = e.restoreText
+		if len(e.candidates) > 0 {
+			restored = e.candidates[0]
+		}
+		e.commitRestoration(restored)
+		e.commitText(string(keyRune))
+		return true, nil
+	}
+	e.commitRestoreText()
+	return false, nil
+}
+
+func (e *IBusBambooEngine) updateRestoreText() {
+	e.updatePreedit(e.restoreText)
+	var restorations = store.restore(e.restoreText, e.lastRestoredWord, maxCompletions)
+	for i, restoration := range restorations {
+		restorations[i] = matchWordsCase(restoration, e.restoreText)
+	}
+	e.openCandidates(restorations, e.commitRestoration)
+}
+
+func (e *IBusBambooEngine) commitRestoration(restoration string) {
+	var words = strings.Fields(restoration)
+	if len(words) == 0 {
+		return
+	}
+	if e.isContentPrivate() {
+		e.commitText(restoration)
+		e.resetPreedit()
+		return
+	}
+	if restoration != strings.TrimSpace(e.restoreText) {
+		store.learnPhrase(strings.Join(words, " "))
+	}
+	e.commitText(restoration)
+	e.resetPreedit()
+	e.lastRestoredWord = words[len(words)-1]
+}
+
+// commitRestoreText commits the plain text as it was typed.
+func (e *IBusBambooEngine) commitRestoreText() {
+	if e.restoreText == "" {
+		return
+	}
+	e.commitText(e.restoreText)
+	e.resetPreedit()
+	e.lastRestoredWord = ""
+}
+
+func (e *IBusBambooEngine) toggleDiacriticRestoration() {
+	e.resetBuffer()
+	e.config.IBflags ^= IBdiacriticRestorationEnabled
+}
+
+// matchWordsCase gives each word of the candidate the case of the typed word,
+// e.g. "Viet Nam" -> "Việt Nam".
+func matchWordsCase(candidate, typed string) string {
+	var candidateWords, typedWords = strings.Fields(candidate), strings.Fields(typed)
+	if len(candidateWords) != len(typedWords) {
+		return matchCase(candidate, typed)
+	}
+	for i := range candidateWords {
+		candidateWords[i] = matchCase(candidateWords[i], typedWords[i])
+	}
+	return strings.Join(candidateWords, " ")
+}
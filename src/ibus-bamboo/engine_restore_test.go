@@ -0,0 +1,113 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// withLearnedWordsFile makes the learned words be saved to a temporary file
+// until the test ends.
+func withLearnedWordsFile(t *testing.T) string {
+	var fileName = withTestFile(t, &getLearnedWordsFile, "ibus-bamboo.learned.dict")
+	t.Cleanup(func() {
+		store.Lock()
+		if store.learnedSave != nil {
+			store.learnedSave.Stop()
+			store.learnedSave = nil
+		}
+		store.Unlock()
+	})
+	return fileName
+}
+
+func TestDiacriticRestoration(t *testing.T) {
+	withLearnedWordsFile(t)
+	withWordDictionary(t, []string{"việt", "viết", "việt nam", "nam", "năm", "năm nay", "năm mới", "là"})
+	var model, _ = bamboo.LoadBigramModel(strings.NewReader("tiếng\tviệt 5\nmột\tnăm 4\tviết 1\n"), 0)
+	store.data.wordDictionary.SetBigramModel(model)
+	var e = newTestEngine(IBstdFlags | IBdiacriticRestorationEnabled)
+	typeString(e, "Viet")
+	if e.restoreText != "Viet" || len(e.candidates) != 2 || e.candidates[0] != "Việt" {
+		t.Errorf("Process [Viet], got [%s] and %v", e.restoreText, e.candidates)
+	}
+	typeString(e, " ")
+	if e.restoreText != "Viet " {
+		t.Errorf("Process [Viet ], expected to keep on typing a phrase, got [%s]", e.restoreText)
+	}
+	typeString(e, "Nam")
+	if len(e.candidates) != 1 || e.candidates[0] != "Việt Nam" {
+		t.Errorf("Process [Viet Nam], got %v", e.candidates)
+	}
+	typeString(e, " ")
+	if e.restoreText != "" || e.lastRestoredWord != "Nam" {
+		t.Errorf("Commit [Viet Nam] with a space, got [%s], last word [%s]", e.restoreText, e.lastRestoredWord)
+	}
+
+	typeString(e, "nam")
+	if len(e.candidates) != 2 || e.candidates[0] != "năm" {
+		t.Errorf("Process [nam], expected the frequent [năm] first, got %v", e.candidates)
+	}
+	e.ProcessKeyEvent(IBUS_BackSpace, 0, 0)
+	if e.restoreText != "na" {
+		t.Errorf("Process BackSpace, expected [na], got [%s]", e.restoreText)
+	}
+	e.ProcessKeyEvent(IBUS_Return, 0, 0)
+	if e.restoreText != "" || e.isCandidateLTOpened || e.lastRestoredWord != "" {
+		t.Errorf("Process Return, expected the plain text to be committed, got [%s]", e.restoreText)
+	}
+
+	typeString(e, "viet")
+	e.ProcessKeyEvent(IBUS_Down, 0, 0)
+	e.ProcessKeyEvent(IBUS_Tab, 0, 0)
+	if e.restoreText != "" || e.lastRestoredWord != "viết" {
+		t.Errorf("Select the second restoration, got [%s], last word [%s]", e.restoreText, e.lastRestoredWord)
+	}
+}
+
+func TestLearnedWordsPersistence(t *testing.T) {
+	var fileName = withLearnedWordsFile(t)
+	withWordDictionary(t, []string{"nam", "năm", "nấm"})
+	store.learnPhrase("nấm")
+	store.learnPhrase("nấm")
+	store.saveLearnedWords()
+	if data, err := ioutil.ReadFile(fileName); err != nil || string(data) != "nấm\t2\n" {
+		t.Errorf("Save the learned words, got [%s] (%v)", data, err)
+	}
+	var dictionary = bamboo.NewDictionary([]string{"nam", "năm", "nấm"})
+	if err := dictionary.ReadCounts(strings.NewReader("nấm\t2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if words := dictionary.Restore("nam", "", 1); len(words) != 1 || words[0] != "nấm" {
+		t.Errorf("Restore [nam] with the saved words, got %q", words)
+	}
+}
+
+func TestMatchWordsCase(t *testing.T) {
+	if s := matchWordsCase("việt nam", "Viet nam"); s != "Việt nam" {
+		t.Errorf("matchWordsCase, got [%s]", s)
+	}
+	if s := matchWordsCase("việt nam", "viet NAM"); s != "việt NAM" {
+		t.Errorf("matchWordsCase, got [%s]", s)
+	}
+}
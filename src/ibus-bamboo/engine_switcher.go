@@ -0,0 +1,176 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"github.com/BambooEngine/goibus/ibus"
+	"sort"
+	"strconv"
+)
+
+const switcherPageSize = 9
+
+// switcherItem is a row of the switcher lookup table, which lists the input
+// methods in the order of the menu and then the output charsets.
+type switcherItem struct {
+	inputMethod string
+	charset     string
+}
+
+func getSwitcherItems(c *Config) []switcherItem {
+	var items []switcherItem
+	var imNames []string
+	for im := range c.InputMethodDefinitions {
+		imNames = append(imNames, im)
+	}
+	for _, im := range sortStrings(imNames) {
+		items = append(items, switcherItem{inputMethod: im})
+	}
+	// Unicode comes first, the others are listed in a map order
+	var charsets = bamboo.GetCharsetNames()
+	sort.Strings(charsets[1:])
+	for _, charset := range charsets {
+		items = append(items, switcherItem{charset: charset})
+	}
+	return items
+}
+
+func (item switcherItem) isCurrent(c *Config) bool {
+	if item.inputMethod != "" {
+		return item.inputMethod == c.InputMethod
+	}
+	return item.charset == c.OutputCharset
+}
+
+func (item switcherItem) String() string {
+	if item.inputMethod != "" {
+		return "Kiểu gõ " + item.inputMethod
+	}
+	return "Bảng mã " + item.charset
+}
+
+func (e *IBusBambooEngine) openSwitcher() bool {
+	if e.isSwitcherLTOpened {
+		return false
+	}
+	// the word being composed is kept, see applySwitcherItem
+	e.stopAutoCommit()
+	e.closeCandidates()
+	e.switcherItems = getSwitcherItems(e.config)
+	lt := ibus.NewLookupTable()
+	lt.PageSize = switcherPageSize
+	lt.Orientation = IBUS_ORIENTATION_VERTICAL
+	for i, item := range e.switcherItems {
+		lt.AppendCandidate(item.String())
+		if item.inputMethod != "" && item.isCurrent(e.config) {
+			lt.SetCursorPos(uint32(i))
+		}
+	}
+	e.switcherLookupTable = lt
+	e.isSwitcherLTOpened = true
+	e.UpdateAuxiliaryText(ibus.NewText("Nhấn (1-9) hoặc Enter để chọn kiểu gõ, bảng mã"), true)
+	e.updateSwitcherLT()
+	return true
+}
+
+// updateSwitcherLT shows the table, the labels of the current page mark the
+// input method and the charset in use with "*".
+func (e *IBusBambooEngine) updateSwitcherLT() {
+	var lt = e.switcherLookupTable
+	var start = lt.CursorPos / lt.PageSize * lt.PageSize
+	lt.Labels = nil
+	for i := start; i < start+lt.PageSize && i < uint32(len(e.switcherItems)); i++ {
+		if e.switcherItems[i].isCurrent(e.config) {
+			lt.AppendLabel("*")
+		} else {
+			lt.AppendLabel(strconv.Itoa(int(i-start) + 1))
+		}
+	}
+	e.UpdateLookupTable(lt, true)
+}
+
+// switcherProcessKeyEvent handles the keys of the switcher, the other keys
+// close it and are processed as if it had not been opened, so that the word
+// being composed goes on.
+func (e *IBusBambooEngine) switcherProcessKeyEvent(keyVal uint32, state uint32) bool {
+	var keyRune = rune(keyVal)
+	switch {
+	case keyVal == IBUS_Escape || e.getHotKeyAction(keyVal, state) == HotKeyOpenSwitcher:
+		e.closeSwitcher()
+	case keyVal == IBUS_Left || keyVal == IBUS_Up:
+		e.cursorUp()
+	case keyVal == IBUS_Right || keyVal == IBUS_Down:
+		e.cursorDown()
+	case keyVal == IBUS_Page_Up:
+		e.pageUp()
+	case keyVal == IBUS_Page_Down:
+		e.pageDown()
+	case keyVal == IBUS_Return:
+		e.commitSwitcherCandidate()
+	case keyRune >= '1' && keyRune <= '9':
+		if e.switcherLookupTable.SetCursorPosInCurrentPage(uint32(keyRune - '1')) {
+			e.commitSwitcherCandidate()
+		}
+	default:
+		e.closeSwitcher()
+		return false
+	}
+	return true
+}
+
+func (e *IBusBambooEngine) commitSwitcherCandidate() {
+	var pos = e.switcherLookupTable.CursorPos
+	var items = e.switcherItems
+	e.closeSwitcher()
+	if pos >= uint32(len(items)) {
+		return
+	}
+	e.applySwitcherItem(items[pos])
+	e.saveToggledOption()
+}
+
+// applySwitcherItem switches to the input method or the charset. The word
+// being composed goes on with the new input method as if it had been typed
+// with it.
+func (e *IBusBambooEngine) applySwitcherItem(item switcherItem) {
+	var oldInputMethod, oldFlags = e.config.InputMethod, e.config.Flags
+	var text = e.getProcessedString(bamboo.VietnameseMode)
+	if item.inputMethod != "" {
+		e.config.InputMethod = item.inputMethod
+	} else if isValidCharset(item.charset) {
+		e.config.OutputCharset = item.charset
+	}
+	e.updatePreeditor(oldInputMethod, oldFlags)
+	if e.config.InputMethod != oldInputMethod && text != "" {
+		e.preeditor.ReplaceLastWord(text)
+	}
+	if e.getRawKeyLen() > 0 && e.inPreeditList() {
+		e.updatePreedit(e.getPreeditString())
+	}
+}
+
+func (e *IBusBambooEngine) closeSwitcher() {
+	e.switcherLookupTable = nil
+	e.switcherItems = nil
+	e.isSwitcherLTOpened = false
+	e.HideLookupTable()
+	e.HideAuxiliaryText()
+}
@@ -0,0 +1,98 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/goibus/ibus"
+	"testing"
+)
+
+func findSwitcherItem(e *IBusBambooEngine, item switcherItem) uint32 {
+	for i, it := range e.switcherItems {
+		if it == item {
+			return uint32(i)
+		}
+	}
+	return 0
+}
+
+// getSwitcherLabel returns the label of the item in the current page.
+func getSwitcherLabel(e *IBusBambooEngine, pos uint32) string {
+	return e.switcherLookupTable.Labels[pos%switcherPageSize].Value().(ibus.Text).Text
+}
+
+func TestOpenSwitcher(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	e.ProcessKeyEvent('~', 0, IBUS_SHIFT_MASK|IBUS_MOD1_MASK)
+	if !e.isSwitcherLTOpened {
+		t.Fatalf("Type Alt+Shift+~, expected the switcher to open")
+	}
+	var lt = e.switcherLookupTable
+	if e.switcherItems[lt.CursorPos].inputMethod != "Telex" {
+		t.Errorf("Open the switcher, expected the cursor on Telex, got %v", e.switcherItems[lt.CursorPos])
+	}
+	if getSwitcherLabel(e, lt.CursorPos) != "*" {
+		t.Errorf("Open the switcher, expected Telex to be marked")
+	}
+	var unicode = findSwitcherItem(e, switcherItem{charset: "Unicode"})
+	for lt.CursorPos/switcherPageSize < unicode/switcherPageSize {
+		e.ProcessKeyEvent(IBUS_Page_Down, 0, 0)
+	}
+	if getSwitcherLabel(e, unicode) != "*" {
+		t.Errorf("Page down to the charsets, expected Unicode to be marked")
+	}
+	e.ProcessKeyEvent(IBUS_Escape, 0, 0)
+	if e.isSwitcherLTOpened || e.config.InputMethod != "Telex" || e.config.OutputCharset != "Unicode" {
+		t.Errorf("Close the switcher, expected no change")
+	}
+}
+
+func TestApplySwitcherItem(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "tieng")
+	var preeditor = e.preeditor
+	e.applySwitcherItem(switcherItem{charset: "VNI Windows"})
+	if e.config.OutputCharset != "VNI Windows" || e.preeditor != preeditor {
+		t.Errorf("Switch the charset, expected the preeditor to be kept")
+	}
+	e.applySwitcherItem(switcherItem{inputMethod: "VNI"})
+	if e.config.InputMethod != "VNI" || e.getPreeditString() != "tieng" {
+		t.Errorf("Switch to VNI, expected the word to be kept, got [%s]", e.getPreeditString())
+	}
+	typeString(e, "61")
+	if e.getPreeditString() != "tiếng" {
+		t.Errorf("Type [61] after switching to VNI, expected [tiếng], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestSwitcherPassesOtherKeys(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "tieeng")
+	e.ProcessKeyEvent('~', 0, IBUS_SHIFT_MASK|IBUS_MOD1_MASK)
+	if !e.isSwitcherLTOpened {
+		t.Fatalf("Type Alt+Shift+~, expected the switcher to open")
+	}
+	if handled, _ := e.ProcessKeyEvent('s', 0, 0); !handled || e.isSwitcherLTOpened {
+		t.Errorf("Type [s] in the switcher, expected the switcher to close and the key to be composed")
+	}
+	if e.getPreeditString() != "tiếng" {
+		t.Errorf("Type [tieeng], the switcher and [s], expected [tiếng], got [%s]", e.getPreeditString())
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"path/filepath"
+	"testing"
+)
+
+// withTestFile makes a file getter like getUserDictionaryFile return a file
+// of a temporary directory until the test ends, the file name is returned.
+func withTestFile(t *testing.T, getFile *func(string) string, baseName string) string {
+	var fileName = filepath.Join(t.TempDir(), baseName)
+	var saved = *getFile
+	*getFile = func(string) string {
+		return fileName
+	}
+	t.Cleanup(func() {
+		*getFile = saved
+	})
+	return fileName
+}
+
+// newTestEngine returns an engine which is not connected to IBus, all the
+// signals it emits are dropped.
+func newTestEngine(ibFlags uint) *IBusBambooEngine {
+	var config = &Config{
+		InputMethod:            "Telex",
+		InputMethodDefinitions: bamboo.InputMethodDefinitions,
+		OutputCharset:          "Unicode",
+		Flags:                  bamboo.EstdFlags,
+		IBflags:                ibFlags,
+		AutoCommitAfter:        3000,
+	}
+	var inputMethod = bamboo.ParseInputMethod(config.InputMethodDefinitions, config.InputMethod)
+	var e = &IBusBambooEngine{
+		engineName: "bamboo",
+		config:     config,
+		preeditor:  bamboo.NewEngine(inputMethod, config.Flags),
+		macroTable: NewMacroTable(),
+	}
+	e.updateHotKeyBindings()
+	return e
+}
+
+func typeString(e *IBusBambooEngine, str string) {
+	for _, chr := range []rune(str) {
+		e.ProcessKeyEvent(uint32(chr), 0, 0)
+	}
+}
+
+func TestProcessKeyEventInPreeditMode(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "vieetj")
+	if e.getPreeditString() != "việt" {
+		t.Errorf("Process [vieetj], expected [việt], got [%s]", e.getPreeditString())
+	}
+	typeString(e, " ")
+	if e.getRawKeyLen() != 0 {
+		t.Errorf("Commit with a space, expected an empty composition, got [%s]", e.preeditor.GetRawString())
+	}
+}
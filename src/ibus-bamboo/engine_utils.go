@@ -40,11 +40,14 @@ func GetBambooEngineCreator() func(conn *dbus.Conn, engineName string) dbus.Obje
 	return func(conn *dbus.Conn, ngName string) dbus.ObjectPath {
 		var engine = new(IBusBambooEngine)
 		var config = LoadConfig(engineName)
-		var inputMethod = bamboo.ParseInputMethod(config.InputMethodDefinitions, config.InputMethod)
+		var inputMethod, _ = parseInputMethod(config)
 		engine.Engine = ibus.BaseEngine(conn, objectPath)
 		engine.engineName = engineName
+		engine.emoji = NewEmojiEngine(nil)
 		engine.preeditor = bamboo.NewEngine(inputMethod, config.Flags)
 		engine.config = LoadConfig(engineName)
+		engine.updateHotKeyBindings()
+		engine.englishModes = loadEnglishModes(getEnglishModeFile(engineName))
 		engine.propList = GetPropListByConfig(config)
 		ibus.PublishEngine(conn, objectPath, engine)
 		go engine.init()
@@ -53,10 +56,17 @@ func GetBambooEngineCreator() func(conn *dbus.Conn, engineName string) dbus.Obje
 	}
 }
 
+// mouseMoved commits the word being composed, the mouse may move the cursor.
+func (e *IBusBambooEngine) mouseMoved() {
+	e.Lock()
+	defer e.Unlock()
+	e.ignorePreedit = false
+	resetCommitClipboard()
+	e.resetFakeBackspace()
+	e.resetBuffer()
+}
+
 func (e *IBusBambooEngine) init() {
-	if e.emoji == nil {
-		e.emoji = NewEmojiEngine()
-	}
 	if e.macroTable == nil {
 		e.macroTable = NewMacroTable()
 		if e.config.IBflags&IBmarcoEnabled != 0 {
@@ -68,14 +78,7 @@ func (e *IBusBambooEngine) init() {
 	if e.config.IBflags&IBautoCommitWithMouseMovement != 0 {
 		startMouseTracking()
 	}
-	onMouseMove = func() {
-		e.Lock()
-		defer e.Unlock()
-		e.ignorePreedit = false
-		x11ClipboardReset()
-		e.resetFakeBackspace()
-		e.resetBuffer()
-	}
+	onMouseMove = e.mouseMoved
 	onMouseClick = func() {
 		e.firstTimeSendingBS = true
 		if e.isEmojiLTOpened {
@@ -107,6 +110,7 @@ func keyPressCapturing() {
 }
 
 func (e *IBusBambooEngine) resetBuffer() {
+	e.commitRestoreText()
 	if e.getRawKeyLen() == 0 {
 		return
 	}
@@ -121,10 +125,8 @@ func (e *IBusBambooEngine) processShiftKey(keyVal, state uint32) bool {
 	if keyVal == IBUS_Shift_L || keyVal == IBUS_Shift_R {
 		// when press one Shift key
 		if state&IBUS_SHIFT_MASK != 0 && state&IBUS_RELEASE_MASK != 0 &&
-			!e.lastKeyWithShift && e.config.IBflags&IBimQuickSwitchEnabled != 0 {
-			e.englishMode = !e.englishMode
-			notify(e.englishMode)
-			e.resetBuffer()
+			!e.lastKeyWithShift && e.getHotKeyAction(0, IBUS_SHIFT_MASK) == HotKeyToggleEnglishMode {
+			e.toggleEnglishMode()
 		}
 		// else
 		if state&IBUS_SHIFT_MASK == 0 && state&IBUS_RELEASE_MASK == 0 &&
@@ -136,6 +138,13 @@ func (e *IBusBambooEngine) processShiftKey(keyVal, state uint32) bool {
 	return false
 }
 
+// isRestoreKey tells whether the word break key restores the key strokes of
+// the word.
+func (e *IBusBambooEngine) isRestoreKey(keyVal, state uint32) bool {
+	return e.config.IBflags&IBrestoreKeyStrokesEnabled != 0 && !e.lastKeyWithShift &&
+		e.getHotKeyAction(keyVal, state) == HotKeyRestoreWord
+}
+
 func (e *IBusBambooEngine) isIgnoredKey(keyVal, state uint32) bool {
 	if state&IBUS_RELEASE_MASK != 0 {
 		//Ignore key-up event
@@ -145,7 +154,7 @@ func (e *IBusBambooEngine) isIgnoredKey(keyVal, state uint32) bool {
 		return true
 	}
 	if e.inExceptedList() {
-		if e.isInputModeLTOpened || keyVal == IBUS_OpenLookupTable {
+		if e.isInputModeLTOpened || e.getHotKeyAction(keyVal, state) == HotKeyOpenInputModeTable {
 			return false
 		}
 		return true
@@ -157,6 +166,17 @@ func (e *IBusBambooEngine) getRawKeyLen() int {
 	return len(e.preeditor.GetRawString())
 }
 
+func (e *IBusBambooEngine) openInputModeTable() bool {
+	if e.config.IBflags&IBinputModeLookupTableEnabled == 0 || e.isInputModeLTOpened || e.wmClasses == "" {
+		return false
+	}
+	e.resetBuffer()
+	e.isInputModeLTOpened = true
+	e.openLookupTable()
+	e.lastKeyWithShift = true
+	return true
+}
+
 func (e *IBusBambooEngine) openLookupTable() {
 	var whiteList = [][]string{
 		e.config.PreeditWhiteList,
@@ -181,16 +201,25 @@ func (e *IBusBambooEngine) openLookupTable() {
 		"Sửa lỗi gạch chân (XTestFakeKeyEvent)",
 		"Sửa lỗi gạch chân (Forward as commit)",
 		"Thêm vào danh sách loại trừ (" + wmClass + ")",
+		"Lưu kiểu gõ, bảng mã và các tùy chọn hiện tại cho " + wmClass,
+		"Xóa cấu hình riêng của " + wmClass,
+	}
+	// the "*" marks the mode in use, whether the application has a profile is
+	// told by the text of the profile rows
+	if _, hasProfile := e.config.AppProfiles[e.wmClasses]; hasProfile {
+		lookupTableConfiguration[7] = "Cập nhật cấu hình riêng của " + wmClass + " theo các tùy chọn hiện tại"
+	} else {
+		lookupTableConfiguration[8] = "Xóa cấu hình riêng của " + wmClass + " (chưa có)"
 	}
 
-	e.UpdateAuxiliaryText(ibus.NewText("Nhấn (1/2/3/4/5/6/7) để lưu tùy chọn của bạn"), true)
+	e.UpdateAuxiliaryText(ibus.NewText("Nhấn (1/2/3/4/5/6/7/8/9) để lưu tùy chọn của bạn"), true)
 
 	lt := ibus.NewLookupTable()
 	lt.PageSize = uint32(len(lookupTableConfiguration))
 	lt.Orientation = IBUS_ORIENTATION_VERTICAL
 	var cursorPos = 0
 	for i := 0; i < len(lookupTableConfiguration); i++ {
-		if inStringList(whiteList[i], e.wmClasses) {
+		if i < len(whiteList) && inStringList(whiteList[i], e.wmClasses) {
 			lt.AppendLabel("*")
 			cursorPos = i
 		} else {
@@ -213,22 +242,22 @@ func (e *IBusBambooEngine) ltProcessKeyEvent(keyVal uint32, keyCode uint32, stat
 	if wmClasses == "" {
 		return true, nil
 	}
-	if keyVal == IBUS_OpenLookupTable {
+	if e.getHotKeyAction(keyVal, state) == HotKeyOpenInputModeTable {
 		e.closeInputModeCandidates()
 		return false, nil
 	}
 	var keyRune = rune(keyVal)
 	if keyVal == IBUS_Left || keyVal == IBUS_Up {
-		e.CursorUp()
+		e.cursorUp()
 		return true, nil
 	} else if keyVal == IBUS_Right || keyVal == IBUS_Down {
-		e.CursorDown()
+		e.cursorDown()
 		return true, nil
 	} else if keyVal == IBUS_Page_Up {
-		e.PageUp()
+		e.pageUp()
 		return true, nil
 	} else if keyVal == IBUS_Page_Down {
-		e.PageDown()
+		e.pageDown()
 		return true, nil
 	}
 	if keyVal == IBUS_Return {
@@ -263,7 +292,9 @@ func (e *IBusBambooEngine) commitInputModeCandidate() {
 		e.config.DirectForwardKeyWhiteList = removeFromWhiteList(e.config.DirectForwardKeyWhiteList, wmClasses)
 		e.config.ExceptedList = removeFromWhiteList(e.config.ExceptedList, wmClasses)
 	}
-	reset()
+	if pos <= 7 {
+		reset()
+	}
 	switch pos {
 	case 1:
 		e.config.PreeditWhiteList = addToWhiteList(e.config.PreeditWhiteList, wmClasses)
@@ -279,9 +310,13 @@ func (e *IBusBambooEngine) commitInputModeCandidate() {
 		e.config.DirectForwardKeyWhiteList = addToWhiteList(e.config.DirectForwardKeyWhiteList, wmClasses)
 	case 7:
 		e.config.ExceptedList = addToWhiteList(e.config.ExceptedList, wmClasses)
+	case 8:
+		e.saveAppProfile(wmClasses)
+	case 9:
+		e.deleteAppProfile(wmClasses)
 	}
 
-	SaveConfig(e.config, e.engineName)
+	e.saveConfig()
 	e.propList = GetPropListByConfig(e.config)
 	e.RegisterProperties(e.propList)
 }
@@ -320,7 +355,17 @@ func (e *IBusBambooEngine) canProcessKey(keyVal, state uint32) bool {
 	if bamboo.IsWordBreakSymbol(keyRune) {
 		return true
 	}
-	return e.preeditor.CanProcessKey(keyRune)
+	return e.preeditor.CanProcessKeyWithModifiers(keyRune, getModifiers(state))
+}
+
+// getModifiers returns the modifiers of the state which input methods can bind
+// keys to.
+func getModifiers(state uint32) bamboo.Modifier {
+	var modifiers bamboo.Modifier
+	if state&IBUS_MOD5_MASK != 0 {
+		modifiers |= bamboo.AltGrModifier
+	}
+	return modifiers
 }
 
 func (e *IBusBambooEngine) inExceptedList() bool {
@@ -0,0 +1,53 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"sort"
+)
+
+// openToneVariants lists the tone and mark variants of the word in the
+// composition, the dictionary words first. It only works in pre-edit mode,
+// where the word can be replaced without sending backspaces.
+func (e *IBusBambooEngine) openToneVariants() bool {
+	if e.getRawKeyLen() == 0 || (e.inBackspaceWhiteList() && !e.inPreeditList()) {
+		return false
+	}
+	var typed = e.getProcessedString(bamboo.VietnameseMode)
+	var variants = bamboo.GenerateVariants(typed, e.config.Flags&bamboo.EstdToneStyle != 0)
+	if len(variants) == 0 {
+		return false
+	}
+	sort.SliceStable(variants, func(i, j int) bool {
+		return store.containsWord(variants[i]) && !store.containsWord(variants[j])
+	})
+	for i, variant := range variants {
+		variants[i] = matchCase(variant, typed)
+	}
+	e.openCandidates(variants, e.replaceWord)
+	return true
+}
+
+func (e *IBusBambooEngine) replaceWord(word string) {
+	e.preeditor.ReplaceLastWord(word)
+	e.updatePreedit(e.getPreeditString())
+	e.scheduleAutoCommit()
+}
@@ -0,0 +1,55 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"testing"
+)
+
+func TestToneVariants(t *testing.T) {
+	withWordDictionary(t, []string{"mặt", "mắt"})
+	var e = newTestEngine(IBstdFlags)
+	e.config.HotKeys = map[string]string{PropKeyToneVariants: "Alt+Down"}
+	e.updateHotKeyBindings()
+	if handled, _ := e.ProcessKeyEvent(IBUS_Down, 0, IBUS_MOD1_MASK); handled {
+		t.Errorf("Tone variants without a composition, expected the key to pass through")
+	}
+	typeString(e, "Mat")
+	if handled, _ := e.ProcessKeyEvent(IBUS_Down, 0, IBUS_MOD1_MASK); !handled || !e.isCandidateLTOpened {
+		t.Fatalf("Tone variants of [Mat], expected the candidates to be shown")
+	}
+	if len(e.candidates) < 2 || !store.containsWord(e.candidates[0]) || !store.containsWord(e.candidates[1]) {
+		t.Errorf("Tone variants of [Mat], expected the dictionary words first, got %v", e.candidates)
+	}
+	for _, candidate := range e.candidates {
+		if candidate == "Mà" || candidate == "Màt" {
+			t.Errorf("Tone variants of [Mat], unexpected %s", candidate)
+		}
+	}
+	e.ProcessKeyEvent(IBUS_Down, 0, 0)
+	e.ProcessKeyEvent(IBUS_Tab, 0, 0)
+	if e.isCandidateLTOpened || e.getPreeditString() != "Mặt" {
+		t.Errorf("Select the second variant, expected [Mặt] in the composition, got [%s]", e.getPreeditString())
+	}
+	typeString(e, "s")
+	if e.getPreeditString() != "Mắt" {
+		t.Errorf("Process [s] after the variant, expected [Mắt], got [%s]", e.getPreeditString())
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func getEnglishDictionaryFile(engineName string) string {
+	return fmt.Sprintf(englishDictFile, getConfigDir(), engineName)
+}
+
+// buildEnglishTrie builds the trie of the English words which are kept as typed
+// when their Vietnamese result is not a word of the dictionary, e.g. "was"
+// rather than "ứa".
+func buildEnglishTrie(dictionaries ...map[string]bool) *bamboo.Node {
+	var trie = &bamboo.Node{}
+	for _, words := range dictionaries {
+		for word := range words {
+			if word != "" {
+				bamboo.AddTrie(trie, []rune(word), false, false)
+			}
+		}
+	}
+	return trie
+}
+
+func isEnglishWord(word string) bool {
+	if word == "" {
+		return false
+	}
+	return store.isEnglishWord(strings.ToLower(word))
+}
+
+func OpenEnglishDictionaryFile(engineName string) {
+	var fileName = getEnglishDictionaryFile(engineName)
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		ioutil.WriteFile(fileName, nil, 0644)
+	}
+	exec.Command("xdg-open", fileName).Start()
+}
+
+// shouldKeepEnglish tells whether the key strokes of the word must not be
+// transformed: the word is in Config.NeverTransformList, or it is an English
+// word whose Vietnamese result is not a word of the dictionary.
+func (e *IBusBambooEngine) shouldKeepEnglish() bool {
+	var rawSeq = e.getProcessedString(bamboo.EnglishMode | bamboo.LowerCase)
+	if rawSeq == "" {
+		return false
+	}
+	if inStringList(e.config.NeverTransformList, rawSeq) {
+		return true
+	}
+	if e.config.IBflags&IBenglishDictEnabled == 0 || !isEnglishWord(rawSeq) {
+		return false
+	}
+	if rawSeq == e.getProcessedString(bamboo.VietnameseMode|bamboo.LowerCase) {
+		return false
+	}
+	return e.preeditor.GetSpellingMatchResult(bamboo.LowerCase, true) != bamboo.FindResultMatchFull
+}
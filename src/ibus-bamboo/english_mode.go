@@ -0,0 +1,184 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// how long the state waits to be written, so that tapping Shift a few
+	// times makes a single write
+	englishModeSaveDelay = 5 * time.Second
+	// the number of windows remembered, the oldest are forgotten first
+	maxEnglishModeWindows = 100
+)
+
+// englishModeState remembers the state of the Vietnamese/English switch for
+// each application, and for each window when IBenglishModePerWindow is on. It
+// is saved to a file so that it survives restarts:
+//
+//	{"Last": true, "Apps": {"xterm:XTerm": true, "skype:Skype": false}}
+type englishModeState struct {
+	Last        bool            // the last state, for the applications which have none yet
+	Apps        map[string]bool // by WM_CLASS
+	windows     map[uint32]bool // by X11 window, the ids do not outlive the X session so they are not saved
+	windowOrder []uint32        // the windows from the oldest to the newest
+	fileName    string
+	mutex       sync.Mutex  // the state is saved by a timer, concurrently with the engine
+	saveTimer   *time.Timer // the pending save
+}
+
+func getEnglishModeFile(engineName string) string {
+	return fmt.Sprintf(englishModeFile, getConfigDir(), engineName)
+}
+
+func loadEnglishModes(fileName string) *englishModeState {
+	var m = &englishModeState{fileName: fileName}
+	if data, err := ioutil.ReadFile(fileName); err == nil {
+		if err = json.Unmarshal(data, m); err != nil {
+			log.Println("Failed to load the English modes:", err)
+		}
+	}
+	if m.Apps == nil {
+		m.Apps = map[string]bool{}
+	}
+	m.windows = map[uint32]bool{}
+	return m
+}
+
+// save writes the state now, a pending delayed save is canceled.
+func (m *englishModeState) save() error {
+	m.mutex.Lock()
+	if m.saveTimer != nil {
+		m.saveTimer.Stop()
+		m.saveTimer = nil
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	m.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(m.fileName, data)
+}
+
+// scheduleSave saves the state a while after it changed, mutex must be held.
+func (m *englishModeState) scheduleSave() {
+	if m.saveTimer != nil {
+		return
+	}
+	m.saveTimer = time.AfterFunc(englishModeSaveDelay, func() {
+		if err := m.save(); err != nil {
+			log.Println("Failed to save the English modes:", err)
+		}
+	})
+}
+
+// lookup returns the state the window, or else the application, was left in,
+// found is false when the switch has never been toggled there.
+func (m *englishModeState) lookup(wmClasses string, window uint32) (englishMode bool, found bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if englishMode, found = m.windows[window]; found && window != 0 {
+		return englishMode, true
+	}
+	englishMode, found = m.Apps[wmClasses]
+	return englishMode, found && wmClasses != ""
+}
+
+func (m *englishModeState) set(wmClasses string, window uint32, englishMode bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.Last = englishMode
+	m.rememberLocked(wmClasses, window, englishMode)
+	m.scheduleSave()
+}
+
+func (m *englishModeState) remember(wmClasses string, window uint32, englishMode bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rememberLocked(wmClasses, window, englishMode)
+}
+
+func (m *englishModeState) rememberLocked(wmClasses string, window uint32, englishMode bool) {
+	if wmClasses != "" {
+		m.Apps[wmClasses] = englishMode
+	}
+	if window == 0 {
+		return
+	}
+	if _, found := m.windows[window]; !found {
+		m.windowOrder = append(m.windowOrder, window)
+		if len(m.windowOrder) > maxEnglishModeWindows {
+			delete(m.windows, m.windowOrder[0])
+			m.windowOrder = m.windowOrder[1:]
+		}
+	}
+	m.windows[window] = englishMode
+}
+
+// getEnglishModeWindow returns the focused window when the switch is
+// remembered for each window, 0 otherwise.
+func (e *IBusBambooEngine) getEnglishModeWindow() uint32 {
+	if e.config.IBflags&IBenglishModePerWindow == 0 {
+		return 0
+	}
+	return e.focusWindow
+}
+
+// restoreEnglishMode brings the switch back to the state the focused window or
+// application was left in. An application which has none yet gets the state
+// of its profile, or else keeps the last state until the switch is toggled
+// there.
+func (e *IBusBambooEngine) restoreEnglishMode() {
+	if e.englishModes == nil || e.config.IBflags&IBimQuickSwitchEnabled == 0 {
+		return
+	}
+	var window = e.getEnglishModeWindow()
+	if englishMode, found := e.englishModes.lookup(e.wmClasses, window); found {
+		e.englishMode = englishMode
+	} else if e.appProfile == nil || e.appProfile.EnglishMode == nil {
+		e.englishMode = e.englishModes.Last
+		e.englishModes.remember(e.wmClasses, window, e.englishMode)
+	}
+}
+
+func (e *IBusBambooEngine) toggleEnglishMode() bool {
+	if e.config.IBflags&IBimQuickSwitchEnabled == 0 {
+		return false
+	}
+	e.setEnglishMode(!e.englishMode)
+	notify(e.englishMode)
+	e.resetBuffer()
+	return true
+}
+
+func (e *IBusBambooEngine) setEnglishMode(englishMode bool) {
+	e.englishMode = englishMode
+	if e.englishModes == nil {
+		return
+	}
+	e.englishModes.set(e.wmClasses, e.getEnglishModeWindow(), englishMode)
+}
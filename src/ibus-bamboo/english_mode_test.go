@@ -0,0 +1,154 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newEnglishModeTestEngine returns an engine which saves the English modes to
+// a temporary file, the pending save is done before the file is removed.
+func newEnglishModeTestEngine(t *testing.T, ibFlags uint) *IBusBambooEngine {
+	var e = newTestEngine(ibFlags | IBimQuickSwitchEnabled)
+	e.englishModes = loadEnglishModes(filepath.Join(t.TempDir(), "ibus-bamboo.english_mode.json"))
+	t.Cleanup(func() {
+		e.englishModes.save()
+	})
+	return e
+}
+
+func TestEnglishModePerApp(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags)
+	e.switchFocus("skype:Skype", 0)
+	e.switchFocus("xterm:XTerm", 0)
+	e.setEnglishMode(true)
+	e.switchFocus("skype:Skype", 0)
+	if e.englishMode {
+		t.Errorf("Focus the chat, expected the Vietnamese mode")
+	}
+	e.switchFocus("xterm:XTerm", 0)
+	if !e.englishMode {
+		t.Errorf("Focus the terminal again, expected the English mode")
+	}
+	e.switchFocus("gedit:Gedit", 0)
+	if !e.englishMode {
+		t.Errorf("Focus a new application, expected the last mode")
+	}
+	e.setEnglishMode(false)
+	e.switchFocus("xterm:XTerm", 0)
+	if !e.englishMode {
+		t.Errorf("Focus the terminal after a toggle in another application, expected the English mode")
+	}
+	e.switchFocus("skype:Skype", 0)
+	if e.englishMode {
+		t.Errorf("Focus the chat after a toggle in another application, expected the Vietnamese mode")
+	}
+}
+
+func TestEnglishModePerWindow(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags|IBenglishModePerWindow)
+	e.switchFocus("xterm:XTerm", 1)
+	e.setEnglishMode(true)
+	e.switchFocus("xterm:XTerm", 2)
+	e.setEnglishMode(false)
+	e.switchFocus("xterm:XTerm", 1)
+	if !e.englishMode {
+		t.Errorf("Focus the first terminal, expected the English mode")
+	}
+	e.switchFocus("xterm:XTerm", 3)
+	if e.englishMode {
+		t.Errorf("Focus a new terminal, expected the last mode of the application")
+	}
+	e.config.IBflags &^= IBenglishModePerWindow
+	e.switchFocus("xterm:XTerm", 1)
+	if e.englishMode {
+		t.Errorf("Focus the first terminal without the option, expected the mode of the application")
+	}
+}
+
+func TestEnglishModeAcrossRestarts(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags)
+	e.switchFocus("xterm:XTerm", 0)
+	e.setEnglishMode(true)
+	e.switchFocus("skype:Skype", 0)
+	e.setEnglishMode(false)
+	if _, err := os.Stat(e.englishModes.fileName); !os.IsNotExist(err) {
+		t.Errorf("Toggle the English mode, expected the file to be written later, got %v", err)
+	}
+	if err := e.englishModes.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var restarted = newTestEngine(IBstdFlags | IBimQuickSwitchEnabled)
+	restarted.englishModes = loadEnglishModes(e.englishModes.fileName)
+	restarted.switchFocus("xterm:XTerm", 0)
+	if !restarted.englishMode {
+		t.Errorf("Focus the terminal after a restart, expected the English mode")
+	}
+	restarted.switchFocus("gedit:Gedit", 0)
+	if restarted.englishMode {
+		t.Errorf("Focus a new application after a restart, expected the last mode")
+	}
+}
+
+func TestEnglishModeWithAppProfile(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags)
+	var englishMode = true
+	e.config.AppProfiles = map[string]AppProfile{"xterm:XTerm": {EnglishMode: &englishMode}}
+	e.switchFocus("xterm:XTerm", 0)
+	if !e.englishMode {
+		t.Errorf("Focus the terminal, expected the English mode of its profile")
+	}
+	e.setEnglishMode(false)
+	e.switchFocus("skype:Skype", 0)
+	e.switchFocus("xterm:XTerm", 0)
+	if e.englishMode {
+		t.Errorf("Focus the terminal after a toggle, expected the Vietnamese mode")
+	}
+}
+
+func TestEnglishModeWithoutQuickSwitch(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags)
+	e.switchFocus("xterm:XTerm", 0)
+	e.setEnglishMode(true)
+	e.config.IBflags &^= IBimQuickSwitchEnabled
+	e.englishMode = false
+	e.switchFocus("skype:Skype", 0)
+	e.switchFocus("xterm:XTerm", 0)
+	if e.englishMode {
+		t.Errorf("Focus the terminal without the quick switch, expected the Vietnamese mode")
+	}
+}
+
+func TestEnglishModeWindowsPruned(t *testing.T) {
+	var e = newEnglishModeTestEngine(t, IBstdFlags|IBenglishModePerWindow)
+	for window := uint32(1); window <= maxEnglishModeWindows+10; window++ {
+		e.switchFocus("xterm:XTerm", window)
+		e.setEnglishMode(window == 1)
+	}
+	if len(e.englishModes.windows) != maxEnglishModeWindows || len(e.englishModes.windowOrder) != maxEnglishModeWindows {
+		t.Errorf("Toggle the English mode in many windows, expected %d windows, got %d", maxEnglishModeWindows, len(e.englishModes.windows))
+	}
+	if _, found := e.englishModes.lookup("", 1); found {
+		t.Errorf("Look up the oldest window, expected it to be forgotten")
+	}
+}
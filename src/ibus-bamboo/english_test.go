@@ -0,0 +1,72 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"testing"
+)
+
+func withEnglishWords(t *testing.T, words ...string) {
+	var saved = store.data.englishTrie
+	var dictionary = map[string]bool{}
+	for _, word := range words {
+		dictionary[word] = true
+	}
+	store.data.englishTrie = buildEnglishTrie(dictionary)
+	t.Cleanup(func() {
+		store.data.englishTrie = saved
+	})
+}
+
+func TestKeepEnglishWords(t *testing.T) {
+	withEnglishWords(t, "test")
+	var e = newTestEngine(IBstdFlags | IBenglishDictEnabled)
+	typeString(e, "tes")
+	if e.getPreeditString() != "té" {
+		t.Errorf("Process [tes], expected [té], got [%s]", e.getPreeditString())
+	}
+	typeString(e, "t")
+	if e.getPreeditString() != "test" || e.getComposedString() != "test" {
+		t.Errorf("Process [test], expected the English word, got [%s]", e.getPreeditString())
+	}
+	e.resetPreedit()
+
+	e.config.IBflags &= ^IBenglishDictEnabled
+	typeString(e, "test")
+	if e.getPreeditString() != "tét" {
+		t.Errorf("Process [test] without the English dictionary, expected [tét], got [%s]", e.getPreeditString())
+	}
+	e.resetPreedit()
+
+	e.config.NeverTransformList = []string{"moon"}
+	typeString(e, "Moon")
+	if e.getPreeditString() != "Moon" {
+		t.Errorf("Process [Moon] in the never transform list, got [%s]", e.getPreeditString())
+	}
+	e.resetPreedit()
+
+	e.config.IBflags |= IBenglishDictEnabled
+	bamboo.AddDictionaryToSpellingTrie(map[string]bool{"tét": true})
+	typeString(e, "test")
+	if e.getPreeditString() != "tét" {
+		t.Errorf("Process [test] when [tét] is a dictionary word, expected [tét], got [%s]", e.getPreeditString())
+	}
+}
@@ -0,0 +1,356 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// the actions of Config.HotKeys which have no property of their own, the other
+// actions are named after the property they run
+const (
+	HotKeyToggleEnglishMode  = "toggle_english_mode"
+	HotKeyCycleInputMethod   = "cycle_input_method"
+	HotKeyOpenEmoji          = "open_emoji"
+	HotKeyOpenInputModeTable = "open_input_mode_table"
+	HotKeyRestoreWord        = "restore_word"
+	HotKeyOpenSwitcher       = "open_switcher"
+)
+
+// defaultHotKeys are the bindings of the actions which Config.HotKeys leaves
+// out, an empty accelerator unbinds an action.
+var defaultHotKeys = map[string]string{
+	HotKeyToggleEnglishMode:  "Shift",
+	HotKeyOpenEmoji:          "Shift+:",
+	HotKeyOpenInputModeTable: "Shift+~",
+	HotKeyRestoreWord:        "Shift+space",
+	HotKeyOpenSwitcher:       "Alt+Shift+~",
+	PropKeyToneVariants:      "Alt+Down",
+}
+
+// hotKeyActions runs the actions which hot keys can be bound to, it tells
+// whether the action consumed the key event. It is filled in init() since the
+// actions look the hot keys up.
+var hotKeyActions map[string]func(e *IBusBambooEngine) bool
+
+// the modifiers which take part in a hot key, Caps Lock and Num Lock do not
+const hotKeyModifierMask = IBUS_SHIFT_MASK | IBUS_CONTROL_MASK | IBUS_MOD1_MASK | IBUS_SUPER_MASK
+
+var hotKeyModifiers = map[string]uint32{
+	"ctrl":    IBUS_CONTROL_MASK,
+	"control": IBUS_CONTROL_MASK,
+	"primary": IBUS_CONTROL_MASK,
+	"shift":   IBUS_SHIFT_MASK,
+	"alt":     IBUS_MOD1_MASK,
+	"super":   IBUS_SUPER_MASK,
+}
+
+var hotKeyNames = map[string]uint32{
+	"space":     IBUS_Space,
+	"tab":       IBUS_Tab,
+	"return":    IBUS_Return,
+	"enter":     IBUS_Return,
+	"escape":    IBUS_Escape,
+	"backspace": IBUS_BackSpace,
+	"insert":    IBUS_Insert,
+	"delete":    IBUS_Delete,
+	"home":      IBUS_Home,
+	"end":       IBUS_End,
+	"page_up":   IBUS_Page_Up,
+	"page_down": IBUS_Page_Down,
+	"left":      IBUS_Left,
+	"up":        IBUS_Up,
+	"right":     IBUS_Right,
+	"down":      IBUS_Down,
+}
+
+func init() {
+	for i := 0; i < 12; i++ {
+		hotKeyNames[fmt.Sprintf("f%d", i+1)] = uint32(IBUS_F1 + i)
+	}
+	hotKeyActions = map[string]func(e *IBusBambooEngine) bool{
+		HotKeyToggleEnglishMode:  (*IBusBambooEngine).toggleEnglishMode,
+		HotKeyCycleInputMethod:   (*IBusBambooEngine).cycleInputMethod,
+		HotKeyOpenEmoji:          (*IBusBambooEngine).openEmojiTable,
+		HotKeyOpenInputModeTable: (*IBusBambooEngine).openInputModeTable,
+		HotKeyOpenSwitcher:       (*IBusBambooEngine).openSwitcher,
+		HotKeyRestoreWord: func(e *IBusBambooEngine) bool {
+			// the word break key restores the word, see isRestoreKey
+			return false
+		},
+		PropKeyToneVariants: (*IBusBambooEngine).openToneVariants,
+		PropKeyMacroTable: func(e *IBusBambooEngine) bool {
+			OpenMactabFile(e.engineName)
+			return true
+		},
+		PropKeyAddToUserDictionary: func(e *IBusBambooEngine) bool {
+			e.addLastWordToUserDictionary()
+			return true
+		},
+		PropKeyWordCompletion: func(e *IBusBambooEngine) bool {
+			e.toggleWordCompletion()
+			e.saveToggledOption()
+			return true
+		},
+		PropKeyDiacriticRestoration: func(e *IBusBambooEngine) bool {
+			e.toggleDiacriticRestoration()
+			e.saveToggledOption()
+			return true
+		},
+	}
+	for action := range clipboardActions {
+		var action = action
+		hotKeyActions[action] = func(e *IBusBambooEngine) bool {
+			e.resetBuffer()
+			// the goroutine gets a copy, e.config may change while it waits for the clipboard
+			var config = *e.config
+			go transformClipboard(&config, action)
+			return true
+		}
+	}
+}
+
+// HotKey is a key with its modifiers. A hot key without a key value is the tap
+// of a lone Shift, it is matched on the release of the key with the key value 0.
+type HotKey struct {
+	KeyVal    uint32
+	Modifiers uint32
+}
+
+// ParseHotKey parses accelerators like "Ctrl+Shift+U", "Alt+F2", "Super+space"
+// or "Shift", and the GTK ones like "<Control><Shift>u".
+func ParseHotKey(accel string) (HotKey, error) {
+	var hk HotKey
+	var modifiers, keyName, err = splitHotKey(accel)
+	if err != nil {
+		return HotKey{}, err
+	}
+	for _, modifier := range modifiers {
+		var mask, found = hotKeyModifiers[strings.ToLower(strings.TrimSpace(modifier))]
+		if !found {
+			return HotKey{}, fmt.Errorf("unknown modifier %q in hot key %q", modifier, accel)
+		}
+		hk.Modifiers |= mask
+	}
+	if mask, found := hotKeyModifiers[keyName]; found && len(modifiers) == 0 {
+		if mask != IBUS_SHIFT_MASK {
+			return HotKey{}, fmt.Errorf("only Shift can be bound alone, got %q", accel)
+		}
+		return HotKey{Modifiers: mask}, nil
+	}
+	if keyVal, found := hotKeyNames[keyName]; found {
+		hk.KeyVal = keyVal
+	} else if chars := []rune(keyName); len(chars) == 1 && chars[0] < 0x7f {
+		hk.KeyVal = uint32(chars[0])
+	} else if keyName != "" {
+		return HotKey{}, fmt.Errorf("unknown key %q in hot key %q", keyName, accel)
+	}
+	if hk.KeyVal == 0 {
+		return HotKey{}, fmt.Errorf("missing key in hot key %q", accel)
+	}
+	return hk, nil
+}
+
+// splitHotKey returns the modifier names and the lower-case key name of an
+// accelerator.
+func splitHotKey(accel string) ([]string, string, error) {
+	var modifiers []string
+	if strings.HasPrefix(accel, "<") {
+		// "<Control><Shift>u"
+		var rest = accel
+		for strings.HasPrefix(rest, "<") {
+			var end = strings.IndexByte(rest, '>')
+			if end < 0 {
+				return nil, "", fmt.Errorf("unclosed modifier in hot key %q", accel)
+			}
+			modifiers = append(modifiers, rest[1:end])
+			rest = rest[end+1:]
+		}
+		return modifiers, strings.ToLower(strings.TrimSpace(rest)), nil
+	}
+	modifiers = strings.Split(accel, "+")
+	var keyName = strings.ToLower(strings.TrimSpace(modifiers[len(modifiers)-1]))
+	modifiers = modifiers[:len(modifiers)-1]
+	if strings.HasSuffix(accel, "++") {
+		// "Ctrl++"
+		keyName = "+"
+		modifiers = modifiers[:len(modifiers)-1]
+	}
+	return modifiers, keyName, nil
+}
+
+// Match compares the key event with the hot key, letters match in both cases
+// since Shift and Caps Lock change the key value. The other symbols match with
+// Shift or not: whether it is needed to type them depends on the keyboard
+// layout, e.g. ':' is shifted in QWERTY and not in AZERTY.
+func (hk HotKey) Match(keyVal, state uint32) bool {
+	if keyVal < 0x7f {
+		keyVal = uint32(unicode.ToLower(rune(keyVal)))
+	}
+	var event = HotKey{keyVal, state & hotKeyModifierMask}
+	return event.layoutFree() == hk.layoutFree()
+}
+
+// layoutFree drops Shift from the hot keys of the symbols which it is part of.
+func (hk HotKey) layoutFree() HotKey {
+	if hk.KeyVal > IBUS_Space && hk.KeyVal < 0x7f && !unicode.IsLetter(rune(hk.KeyVal)) {
+		hk.Modifiers &^= IBUS_SHIFT_MASK
+	}
+	return hk
+}
+
+func (hk HotKey) String() string {
+	var parts []string
+	for _, m := range []struct {
+		mask uint32
+		name string
+	}{{IBUS_CONTROL_MASK, "Ctrl"}, {IBUS_SUPER_MASK, "Super"}, {IBUS_MOD1_MASK, "Alt"}, {IBUS_SHIFT_MASK, "Shift"}} {
+		if hk.Modifiers&m.mask != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	if hk.KeyVal == 0 {
+		return strings.Join(parts, "+")
+	}
+	var key = string(unicode.ToUpper(rune(hk.KeyVal)))
+	for name, keyVal := range hotKeyNames {
+		if keyVal == hk.KeyVal && name != "enter" {
+			key = strings.ToUpper(name[:1]) + name[1:]
+		}
+	}
+	return strings.Join(append(parts, key), "+")
+}
+
+type hotKeyBinding struct {
+	action string
+	hotKey HotKey
+}
+
+// getHotKeyBindings returns the bindings of Config.HotKeys and of the default
+// hot keys which it leaves out, in this order and then by action. A binding
+// which is invalid or which takes the hot key of an earlier one is skipped and
+// reported.
+func getHotKeyBindings(c *Config) ([]hotKeyBinding, []error) {
+	var actions, defaultActions []string
+	for action := range c.HotKeys {
+		actions = append(actions, action)
+	}
+	for action := range defaultHotKeys {
+		if _, found := c.HotKeys[action]; !found {
+			defaultActions = append(defaultActions, action)
+		}
+	}
+	sort.Strings(actions)
+	sort.Strings(defaultActions)
+	var bindings []hotKeyBinding
+	var errs []error
+	for _, action := range append(actions, defaultActions...) {
+		var accel, found = c.HotKeys[action]
+		if !found {
+			accel = defaultHotKeys[action]
+		}
+		if accel == "" {
+			continue
+		}
+		if _, found := hotKeyActions[action]; !found {
+			errs = append(errs, fmt.Errorf("unknown action %q", action))
+			continue
+		}
+		var hk, err = ParseHotKey(accel)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = checkHotKey(action, hk, bindings); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bindings = append(bindings, hotKeyBinding{action, hk})
+	}
+	return bindings, errs
+}
+
+func checkHotKey(action string, hk HotKey, bindings []hotKeyBinding) error {
+	for _, binding := range bindings {
+		if binding.hotKey.layoutFree() == hk.layoutFree() {
+			return fmt.Errorf("hot key %s of %q is already bound to %q", hk, action, binding.action)
+		}
+	}
+	if action == HotKeyRestoreWord && (hk.Modifiers&^IBUS_SHIFT_MASK != 0 || !bamboo.IsWordBreakSymbol(rune(hk.KeyVal))) {
+		return fmt.Errorf("hot key %s of %q must be a word break key, with Shift or not", hk, action)
+	}
+	if action == HotKeyToggleEnglishMode && hk.KeyVal == 0 {
+		return nil
+	}
+	if hk.KeyVal == 0 {
+		return fmt.Errorf("hot key %s of %q is kept for %q", hk, action, HotKeyToggleEnglishMode)
+	}
+	return nil
+}
+
+// updateHotKeyBindings parses the hot keys of the configuration, once for all
+// the key events: it must be called again when Config.HotKeys changes.
+func (e *IBusBambooEngine) updateHotKeyBindings() {
+	var errs []error
+	e.hotKeyBindings, errs = getHotKeyBindings(e.config)
+	for _, err := range errs {
+		log.Println("Hot key:", err)
+	}
+}
+
+// getHotKeyAction returns the action bound to the key event.
+func (e *IBusBambooEngine) getHotKeyAction(keyVal, state uint32) string {
+	for _, binding := range e.hotKeyBindings {
+		if binding.hotKey.Match(keyVal, state) {
+			return binding.action
+		}
+	}
+	return ""
+}
+
+func getHotKeyLabel(c *Config, action string) string {
+	var bindings, _ = getHotKeyBindings(c)
+	for _, binding := range bindings {
+		if binding.action == action {
+			return " (" + binding.hotKey.String() + ")"
+		}
+	}
+	return ""
+}
+
+// runHotKeyAction tells whether the action consumed the key event.
+func (e *IBusBambooEngine) runHotKeyAction(action string) bool {
+	if run, found := hotKeyActions[action]; found {
+		return run(e)
+	}
+	return false
+}
+
+// saveToggledOption shows the new state of an option switched by a hot key.
+func (e *IBusBambooEngine) saveToggledOption() {
+	e.propList = GetPropListByConfig(e.config)
+	e.RegisterProperties(e.propList)
+	e.saveConfig()
+}
@@ -0,0 +1,177 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"reflect"
+	"testing"
+)
+
+func TestParseHotKey(t *testing.T) {
+	var tests = []struct {
+		accel string
+		hk    HotKey
+	}{
+		{"Ctrl+Shift+U", HotKey{'u', IBUS_CONTROL_MASK | IBUS_SHIFT_MASK}},
+		{"alt+F2", HotKey{IBUS_F1 + 1, IBUS_MOD1_MASK}},
+		{"Super+space", HotKey{IBUS_Space, IBUS_SUPER_MASK}},
+		{"Ctrl++", HotKey{'+', IBUS_CONTROL_MASK}},
+		{"<Control><Shift>v", HotKey{'v', IBUS_CONTROL_MASK | IBUS_SHIFT_MASK}},
+		{"<Primary>F5", HotKey{IBUS_F1 + 4, IBUS_CONTROL_MASK}},
+		{"Shift", HotKey{0, IBUS_SHIFT_MASK}},
+	}
+	for _, test := range tests {
+		var hk, err = ParseHotKey(test.accel)
+		if err != nil || hk != test.hk {
+			t.Errorf("Parse hot key %s, expected %v, got %v (%v)", test.accel, test.hk, hk, err)
+		}
+	}
+	for _, accel := range []string{"", "Ctrl+", "Hyper+a", "Ctrl+Shift+foo", "<Control", "<Hyper>a", "<Shift>", "Ctrl"} {
+		if _, err := ParseHotKey(accel); err == nil {
+			t.Errorf("Parse hot key %q, expected an error", accel)
+		}
+	}
+}
+
+func TestMatchHotKey(t *testing.T) {
+	var hk, _ = ParseHotKey("Ctrl+Shift+U")
+	if !hk.Match('U', IBUS_CONTROL_MASK|IBUS_SHIFT_MASK) {
+		t.Errorf("Match Ctrl+Shift+U, expected true")
+	}
+	if !hk.Match('u', IBUS_CONTROL_MASK|IBUS_SHIFT_MASK|IBUS_LOCK_MASK) {
+		t.Errorf("Match Ctrl+Shift+U with Caps Lock, expected true")
+	}
+	if hk.Match('U', IBUS_SHIFT_MASK) {
+		t.Errorf("Match Shift+U, expected false")
+	}
+	if hk.String() != "Ctrl+Shift+U" {
+		t.Errorf("Format hot key, expected [Ctrl+Shift+U], got [%s]", hk.String())
+	}
+}
+
+func TestMatchSymbolHotKey(t *testing.T) {
+	var hk, _ = ParseHotKey("Shift+:")
+	if !hk.Match(':', IBUS_SHIFT_MASK) || !hk.Match(':', 0) {
+		t.Errorf("Match Shift+: with a QWERTY and an AZERTY keyboard, expected true")
+	}
+	if hk.Match(':', IBUS_CONTROL_MASK) || hk.Match(';', IBUS_SHIFT_MASK) {
+		t.Errorf("Match Ctrl+: and Shift+;, expected false")
+	}
+	hk, _ = ParseHotKey("Shift+space")
+	if hk.Match(IBUS_Space, 0) {
+		t.Errorf("Match Space with Shift+space, expected false")
+	}
+	if _, errs := getHotKeyBindings(&Config{HotKeys: map[string]string{PropKeyMacroTable: ":"}}); len(errs) != 1 {
+		t.Errorf("Bind [:] next to the default Shift+:, expected a conflict, got %v", errs)
+	}
+}
+
+func TestClipboardHotKeyAction(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	e.config.HotKeys = map[string]string{PropKeyClipboardUpperCase: "Ctrl+Shift+F9"}
+	e.updateHotKeyBindings()
+	if action := e.getHotKeyAction(IBUS_F1+8, IBUS_CONTROL_MASK|IBUS_SHIFT_MASK); action != PropKeyClipboardUpperCase {
+		t.Errorf("Get the action of Ctrl+Shift+F9, expected [%s], got [%s]", PropKeyClipboardUpperCase, action)
+	}
+	if label := getHotKeyLabel(e.config, PropKeyClipboardUpperCase); label != " (Ctrl+Shift+F9)" {
+		t.Errorf("Get the label of Ctrl+Shift+F9, got [%s]", label)
+	}
+}
+
+func TestHotKeyBindings(t *testing.T) {
+	var c = &Config{HotKeys: map[string]string{
+		HotKeyCycleInputMethod: "<Shift>space",
+		HotKeyOpenEmoji:        "",
+		"open_everything":      "Ctrl+E",
+	}}
+	var bindings, errs = getHotKeyBindings(c)
+	if len(errs) != 2 {
+		t.Errorf("Check the hot keys, expected an unknown action and a conflict, got %v", errs)
+	}
+	var actions = map[string]string{}
+	for _, binding := range bindings {
+		actions[binding.hotKey.String()] = binding.action
+	}
+	var expected = map[string]string{
+		"Shift+Space": HotKeyCycleInputMethod,
+		"Shift":       HotKeyToggleEnglishMode,
+		"Shift+~":     HotKeyOpenInputModeTable,
+		"Alt+Shift+~": HotKeyOpenSwitcher,
+		"Alt+Down":    PropKeyToneVariants,
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("Bind the hot keys, expected %v, got %v", expected, actions)
+	}
+	if label := getHotKeyLabel(c, HotKeyRestoreWord); label != "" {
+		t.Errorf("Get the label of a hot key taken by another action, got [%s]", label)
+	}
+	if label := getHotKeyLabel(c, HotKeyToggleEnglishMode); label != " (Shift)" {
+		t.Errorf("Get the label of the Shift tap, got [%s]", label)
+	}
+	c.HotKeys = map[string]string{HotKeyRestoreWord: "Ctrl+R", HotKeyCycleInputMethod: "Shift"}
+	if _, errs = getHotKeyBindings(c); len(errs) != 2 {
+		t.Errorf("Bind the restore to a letter and the cycle to the Shift tap, expected 2 errors, got %v", errs)
+	}
+}
+
+func TestRebindHotKeys(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBrestoreKeyStrokesEnabled | IBimQuickSwitchEnabled)
+	e.wmClasses = "gedit:Gedit"
+	if !e.isRestoreKey(IBUS_Space, IBUS_SHIFT_MASK) || e.getHotKeyAction(0, IBUS_SHIFT_MASK) != HotKeyToggleEnglishMode {
+		t.Errorf("Expected the default hot keys for the restore and the quick switch")
+	}
+	e.config.HotKeys = map[string]string{
+		HotKeyOpenInputModeTable: "<Control>grave",
+		HotKeyToggleEnglishMode:  "Ctrl+space",
+		HotKeyRestoreWord:        "Shift+?",
+	}
+	if _, errs := getHotKeyBindings(e.config); len(errs) != 1 {
+		t.Errorf("Bind an unknown key name, expected an error, got %v", errs)
+	}
+	e.config.HotKeys[HotKeyOpenInputModeTable] = "Ctrl+`"
+	e.updateHotKeyBindings()
+	e.ProcessKeyEvent('~', 0, IBUS_SHIFT_MASK)
+	if e.isInputModeLTOpened {
+		t.Errorf("Type Shift+~ after a rebinding, expected the input mode table to stay closed")
+	}
+	e.ProcessKeyEvent('`', 0, IBUS_CONTROL_MASK)
+	if !e.isInputModeLTOpened {
+		t.Errorf("Type Ctrl+`, expected the input mode table to open")
+	}
+	e.lastKeyWithShift = false
+	if e.isRestoreKey(IBUS_Space, IBUS_SHIFT_MASK) || !e.isRestoreKey('?', IBUS_SHIFT_MASK) {
+		t.Errorf("Expected Shift+? to restore the key strokes instead of Shift+Space")
+	}
+	if e.getHotKeyAction(0, IBUS_SHIFT_MASK) != "" {
+		t.Errorf("Expected the Shift tap to be free after a rebinding of the quick switch")
+	}
+}
+
+func TestNextInputMethod(t *testing.T) {
+	var c = &Config{InputMethod: "Telex", InputMethodDefinitions: map[string]bamboo.InputMethodDefinition{
+		"Telex": nil, "VNI": nil, "VIQR": nil,
+	}}
+	for _, expected := range []string{"VIQR", "VNI", "Telex"} {
+		c.InputMethod = nextInputMethod(c)
+		if c.InputMethod != expected {
+			t.Errorf("Cycle the input methods, expected [%s], got [%s]", expected, c.InputMethod)
+		}
+	}
+}
@@ -74,6 +74,7 @@ const (
 	IBUS_Page_Up          = 0xFF55
 	IBUS_Page_Down        = 0xFF56
 	IBUS_BackSpace        = 0xff08
+	IBUS_Tab              = 0xff09
 	IBUS_Return           = 0xff0d
 	IBUS_Escape           = 0xff1b
 	IBUS_Shift_L          = 0xffe1
@@ -84,6 +85,10 @@ const (
 	IBUS_Insert           = 0xff63
 	IBUS_Deadkey_Currency = 0xfe6f
 	IBUS_Caps_Lock        = 0xffe5
+	IBUS_Home             = 0xff50
+	IBUS_End              = 0xff57
+	IBUS_Delete           = 0xffff
+	IBUS_F1               = 0xffbe
 	IBUS_OpenLookupTable  = IBUS_TILDE
 	IBUS_OpenEmojiTable   = IBUS_Colon
 )
@@ -93,3 +98,26 @@ const (
 	IBUS_ORIENTATION_VERTICAL   = 1
 	IBUS_ORIENTATION_SYSTEM     = 2
 )
+
+const (
+	//IBusInputPurpose
+	IBUS_INPUT_PURPOSE_FREE_FORM = 0
+	IBUS_INPUT_PURPOSE_ALPHA     = 1
+	IBUS_INPUT_PURPOSE_DIGITS    = 2
+	IBUS_INPUT_PURPOSE_NUMBER    = 3
+	IBUS_INPUT_PURPOSE_PHONE     = 4
+	IBUS_INPUT_PURPOSE_URL       = 5
+	IBUS_INPUT_PURPOSE_EMAIL     = 6
+	IBUS_INPUT_PURPOSE_NAME      = 7
+	IBUS_INPUT_PURPOSE_PASSWORD  = 8
+	IBUS_INPUT_PURPOSE_PIN       = 9
+	IBUS_INPUT_PURPOSE_TERMINAL  = 10
+)
+
+const (
+	//IBusInputHints
+	IBUS_INPUT_HINT_NONE          = 0
+	IBUS_INPUT_HINT_SPELLCHECK    = 1 << 0
+	IBUS_INPUT_HINT_NO_SPELLCHECK = 1 << 1
+	IBUS_INPUT_HINT_PRIVATE       = 1 << 11 //the text must not be stored, e.g. for learning
+)
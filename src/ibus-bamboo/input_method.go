@@ -0,0 +1,197 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"github.com/BambooEngine/goibus/ibus"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"reflect"
+	"time"
+)
+
+// how long the notice of a broken input method stays, it is a variable for
+// the tests
+var inputMethodErrorsTimeout = 10 * time.Second
+
+type InputMethodDefinitions map[string]bamboo.InputMethodDefinition
+
+func loadInputMethodDefinitions(fileName string) (InputMethodDefinitions, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var definitions = InputMethodDefinitions{}
+	if err = json.Unmarshal(data, &definitions); err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return definitions, nil
+}
+
+// mergeInputMethodDefinitions loads the system definitions and then the user
+// ones, a definition of the user replaces the system one of the same name.
+// The built-in definitions are used when the system file is missing.
+func mergeInputMethodDefinitions(systemFile, userFile string) InputMethodDefinitions {
+	var definitions = InputMethodDefinitions{}
+	systemDefinitions, err := loadInputMethodDefinitions(systemFile)
+	if err != nil {
+		log.Println(err)
+		systemDefinitions = bamboo.InputMethodDefinitions
+	}
+	for name, def := range systemDefinitions {
+		definitions[name] = def
+	}
+	userDefinitions, err := loadInputMethodDefinitions(userFile)
+	if err != nil && !os.IsNotExist(err) {
+		log.Println(err)
+	}
+	for name, def := range userDefinitions {
+		definitions[name] = def
+	}
+	return definitions
+}
+
+func getInputMethodFile(engineName string) string {
+	return fmt.Sprintf(inputMethodFile, getConfigDir(), engineName)
+}
+
+func getInputMethodDefinitions(engineName string) InputMethodDefinitions {
+	return mergeInputMethodDefinitions(getEngineSubFile(InputMethodFile), getInputMethodFile(engineName))
+}
+
+func saveInputMethodDefinitions(fileName string, definitions InputMethodDefinitions) error {
+	data, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// Older versions saved every definition into the config file, only those which
+// differ from the built-in ones are worth moving to the user's definition file.
+func migrateInputMethodDefinitions(legacy InputMethodDefinitions, userFile string) {
+	if len(legacy) == 0 {
+		return
+	}
+	if _, err := os.Stat(userFile); !os.IsNotExist(err) {
+		return
+	}
+	var customized = InputMethodDefinitions{}
+	for name, def := range legacy {
+		if builtin, found := bamboo.InputMethodDefinitions[name]; !found || !reflect.DeepEqual(builtin, def) {
+			customized[name] = def
+		}
+	}
+	if len(customized) == 0 {
+		return
+	}
+	if err := saveInputMethodDefinitions(userFile, customized); err != nil {
+		log.Println(err)
+	}
+}
+
+func OpenInputMethodFile(engineName string) {
+	efPath := getInputMethodFile(engineName)
+	if _, err := os.Stat(efPath); os.IsNotExist(err) {
+		ioutil.WriteFile(efPath, []byte("{}\n"), 0644)
+	}
+
+	exec.Command("xdg-open", efPath).Start()
+}
+
+// parseInputMethod falls back to the built-in Telex when the selected input
+// method is unknown or has broken lines, so that the engine keeps working.
+func parseInputMethod(config *Config) (bamboo.InputMethod, []error) {
+	var inputMethod, errs = bamboo.ValidateInputMethod(config.InputMethodDefinitions, config.InputMethod)
+	if len(errs) == 0 {
+		return inputMethod, nil
+	}
+	for _, err := range errs {
+		log.Println(err)
+	}
+	return bamboo.ParseInputMethod(bamboo.InputMethodDefinitions, "Telex"), errs
+}
+
+// showInputMethodErrors tells that the input method falls back to Telex, the
+// notice goes away after a while or when the input method changes. Without
+// errors, a notice of the previous input method is hidden.
+func (e *IBusBambooEngine) showInputMethodErrors(errs []error) {
+	if len(errs) == 0 {
+		e.hideInputMethodErrors()
+		return
+	}
+	var msg = fmt.Sprintf("Kiểu gõ %s có %d lỗi, tạm dùng Telex (%v)", e.config.InputMethod, len(errs), errs[0])
+	e.UpdateAuxiliaryText(ibus.NewText(msg), true)
+	e.isIMErrorShown = true
+	e.imErrorSeq++
+	var seq = e.imErrorSeq
+	time.AfterFunc(inputMethodErrorsTimeout, func() {
+		e.Lock()
+		defer e.Unlock()
+		// a newer notice has its own timeout
+		if seq == e.imErrorSeq {
+			e.hideInputMethodErrors()
+		}
+	})
+}
+
+func (e *IBusBambooEngine) hideInputMethodErrors() {
+	if !e.isIMErrorShown {
+		return
+	}
+	e.isIMErrorShown = false
+	// a table opened since then has replaced the notice with its own text
+	if !e.isInputModeLTOpened && !e.isEmojiLTOpened && !e.isSwitcherLTOpened {
+		e.HideAuxiliaryText()
+	}
+}
+
+// nextInputMethod returns the input method after the one in use, in the order
+// of the menu.
+func nextInputMethod(c *Config) string {
+	var imNames []string
+	for im := range c.InputMethodDefinitions {
+		imNames = append(imNames, im)
+	}
+	imNames = sortStrings(imNames)
+	for i, im := range imNames {
+		if im == c.InputMethod && i+1 < len(imNames) {
+			return imNames[i+1]
+		}
+	}
+	if len(imNames) == 0 {
+		return c.InputMethod
+	}
+	return imNames[0]
+}
+
+func (e *IBusBambooEngine) cycleInputMethod() bool {
+	var oldInputMethod, oldFlags = e.config.InputMethod, e.config.Flags
+	e.resetBuffer()
+	e.config.InputMethod = nextInputMethod(e.config)
+	e.updatePreeditor(oldInputMethod, oldFlags)
+	e.saveToggledOption()
+	return true
+}
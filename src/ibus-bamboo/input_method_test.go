@@ -0,0 +1,140 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package main
+
+import (
+	"errors"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMergeInputMethodDefinitions(t *testing.T) {
+	var dir = t.TempDir()
+	var userFile = filepath.Join(dir, "ibus-bamboo.input_method.json")
+	var userDefs = `{
+		"Telex": {"s": "DauSac", "f": "DauHuyen", "a": "A_Â", "e": "E_Ê", "o": "O_Ô", "w": "UOA_ƯƠĂ", "d": "D_Đ"},
+		"My Telex": {"s": "DauSac"}
+	}`
+	if err := ioutil.WriteFile(userFile, []byte(userDefs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var defs = mergeInputMethodDefinitions("../../"+InputMethodFile, userFile)
+	if len(defs) != len(bamboo.InputMethodDefinitions)+1 {
+		t.Errorf("Merge definitions, expected %d input methods, got %d", len(bamboo.InputMethodDefinitions)+1, len(defs))
+	}
+	if _, found := defs["Telex"]["j"]; found {
+		t.Errorf("Merge definitions, expected the user's Telex to take precedence")
+	}
+	if len(defs["VNI"]) != len(bamboo.InputMethodDefinitions["VNI"]) {
+		t.Errorf("Merge definitions, expected the system VNI, got %v", defs["VNI"])
+	}
+}
+
+func TestMergeInputMethodDefinitionsWithoutFiles(t *testing.T) {
+	var dir = t.TempDir()
+	var defs = mergeInputMethodDefinitions(filepath.Join(dir, "system.json"), filepath.Join(dir, "user.json"))
+	if len(defs) != len(bamboo.InputMethodDefinitions) {
+		t.Errorf("Merge definitions, expected the built-in input methods, got %d", len(defs))
+	}
+}
+
+func TestSystemInputMethodDefinitions(t *testing.T) {
+	var defs, err = loadInputMethodDefinitions("../../" + InputMethodFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range bamboo.InputMethodDefinitions {
+		if len(defs[name]) != len(def) {
+			t.Errorf("Input method %s, expected %d keys in %s, got %d", name, len(def), InputMethodFile, len(defs[name]))
+		}
+	}
+}
+
+func TestMigrateInputMethodDefinitions(t *testing.T) {
+	var userFile = filepath.Join(t.TempDir(), "ibus-bamboo.input_method.json")
+	var legacy = InputMethodDefinitions{}
+	for name, def := range bamboo.InputMethodDefinitions {
+		legacy[name] = def
+	}
+	migrateInputMethodDefinitions(legacy, userFile)
+	if _, err := os.Stat(userFile); !os.IsNotExist(err) {
+		t.Errorf("Migrate built-in definitions, expected no user file")
+	}
+	legacy["VNI"] = bamboo.InputMethodDefinition{"1": "DauSac"}
+	migrateInputMethodDefinitions(legacy, userFile)
+	var defs, err = loadInputMethodDefinitions(userFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 1 || len(defs["VNI"]) != 1 {
+		t.Errorf("Migrate customized definitions, expected only VNI, got %v", defs)
+	}
+}
+
+func TestParseBrokenInputMethod(t *testing.T) {
+	var config = &Config{
+		InputMethod: "Broken",
+		InputMethodDefinitions: map[string]bamboo.InputMethodDefinition{
+			"Broken": {"s": "DauSacc"},
+		},
+	}
+	var im, errs = parseInputMethod(config)
+	if len(errs) != 1 {
+		t.Errorf("Parse a broken input method, expected 1 error, got %v", errs)
+	}
+	if im.Name != "Telex" {
+		t.Errorf("Parse a broken input method, expected to fall back to Telex, got [%s]", im.Name)
+	}
+	var e = newTestEngine(IBstdFlags)
+	e.showInputMethodErrors(errs)
+	typeString(e, "a")
+	if !e.isIMErrorShown {
+		t.Errorf("Process a key, expected the input method errors to stay")
+	}
+	e.showInputMethodErrors(nil)
+	if e.isIMErrorShown {
+		t.Errorf("Switch to a valid input method, expected the input method errors to be hidden")
+	}
+}
+
+func TestInputMethodErrorsTimeout(t *testing.T) {
+	var timeout = inputMethodErrorsTimeout
+	inputMethodErrorsTimeout = time.Millisecond
+	defer func() {
+		inputMethodErrorsTimeout = timeout
+	}()
+	var e = newTestEngine(IBstdFlags)
+	e.Lock()
+	e.showInputMethodErrors([]error{errors.New("broken")})
+	e.Unlock()
+	for i := 0; i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+		e.Lock()
+		var shown = e.isIMErrorShown
+		e.Unlock()
+		if !shown {
+			return
+		}
+	}
+	t.Errorf("Wait after the input method errors, expected them to be hidden")
+}
@@ -0,0 +1,98 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"unicode"
+)
+
+// usQwertyKeys maps the keycodes IBus sends (evdev codes, i.e. the X11 keycode
+// minus 8) to the characters of the key on a US-QWERTY keyboard, unshifted and
+// shifted.
+var usQwertyKeys = map[uint32][2]rune{
+	2: {'1', '!'}, 3: {'2', '@'}, 4: {'3', '#'}, 5: {'4', '$'}, 6: {'5', '%'},
+	7: {'6', '^'}, 8: {'7', '&'}, 9: {'8', '*'}, 10: {'9', '('}, 11: {'0', ')'},
+	12: {'-', '_'}, 13: {'=', '+'},
+	16: {'q', 'Q'}, 17: {'w', 'W'}, 18: {'e', 'E'}, 19: {'r', 'R'}, 20: {'t', 'T'},
+	21: {'y', 'Y'}, 22: {'u', 'U'}, 23: {'i', 'I'}, 24: {'o', 'O'}, 25: {'p', 'P'},
+	26: {'[', '{'}, 27: {']', '}'},
+	30: {'a', 'A'}, 31: {'s', 'S'}, 32: {'d', 'D'}, 33: {'f', 'F'}, 34: {'g', 'G'},
+	35: {'h', 'H'}, 36: {'j', 'J'}, 37: {'k', 'K'}, 38: {'l', 'L'},
+	39: {';', ':'}, 40: {'\'', '"'}, 41: {'`', '~'}, 43: {'\\', '|'},
+	44: {'z', 'Z'}, 45: {'x', 'X'}, 46: {'c', 'C'}, 47: {'v', 'V'}, 48: {'b', 'B'},
+	49: {'n', 'N'}, 50: {'m', 'M'},
+	51: {',', '<'}, 52: {'.', '>'}, 53: {'/', '?'},
+}
+
+// getModifiedKeyVal returns the key which the input method binds under the
+// modifiers of the state, e.g. the "d" of AltGr+d, instead of the third level
+// character of the layout. Such keys are named by their US-QWERTY position.
+func (e *IBusBambooEngine) getModifiedKeyVal(keyVal, keyCode, state uint32) uint32 {
+	var modifiers = getModifiers(state)
+	if modifiers == 0 || state&IBUS_RELEASE_MASK != 0 {
+		return keyVal
+	}
+	var chr, found = getQwertyChar(keyCode, state)
+	if found && e.preeditor.CanProcessKeyWithModifiers(chr, modifiers) {
+		return uint32(chr)
+	}
+	return keyVal
+}
+
+// getQwertyKeyVal returns the key the input method should see when the keys
+// are read by their position: the US-QWERTY character of the key if the input
+// method uses it, the keyval of the layout otherwise. ok is false for a key
+// whose US-QWERTY character has no role in the input method while the
+// character of the layout would be taken as one, e.g. the "s" of Dvorak which
+// sits where US-QWERTY has ";". Such a key must reach the application as is.
+func (e *IBusBambooEngine) getQwertyKeyVal(keyVal, keyCode, state uint32) (qwertyKeyVal uint32, ok bool) {
+	// AltGr picks the third level of the layout, which has no US-QWERTY
+	// counterpart
+	if state&IBUS_RELEASE_MASK != 0 || state&IBUS_MOD5_MASK != 0 || !e.isValidState(state) {
+		return keyVal, true
+	}
+	var chr, found = getQwertyChar(keyCode, state)
+	if !found {
+		return keyVal, true
+	}
+	if e.preeditor.CanProcessKey(chr) {
+		return uint32(chr), true
+	}
+	if keyVal < 0xff00 && e.preeditor.CanProcessKey(rune(keyVal)) {
+		return keyVal, false
+	}
+	return keyVal, true
+}
+
+// getQwertyChar returns the character of the key on a US-QWERTY keyboard.
+func getQwertyChar(keyCode, state uint32) (rune, bool) {
+	var chars, found = usQwertyKeys[keyCode]
+	if !found {
+		return 0, false
+	}
+	var shifted = state&IBUS_SHIFT_MASK != 0
+	if state&IBUS_LOCK_MASK != 0 && unicode.IsLetter(chars[0]) {
+		shifted = !shifted
+	}
+	if shifted {
+		return chars[1], true
+	}
+	return chars[0], true
+}
@@ -0,0 +1,121 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"testing"
+)
+
+type keyPress struct {
+	keyCode uint32
+	keyVal  rune
+	state   uint32
+}
+
+func typeKeys(e *IBusBambooEngine, keys []keyPress) (handled []bool) {
+	for _, key := range keys {
+		var ok, _ = e.ProcessKeyEvent(uint32(key.keyVal), key.keyCode, key.state)
+		handled = append(handled, ok)
+	}
+	return handled
+}
+
+func TestKeyCodeLayoutDvorak(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBkeyCodeLayoutEnabled)
+	// V i e e t j, where Dvorak has K c . . y h
+	typeKeys(e, []keyPress{{47, 'K', IBUS_SHIFT_MASK}, {23, 'c', 0}, {18, '.', 0}, {18, '.', 0}, {20, 'y', 0}, {36, 'h', 0}})
+	if e.getPreeditString() != "Việt" {
+		t.Errorf("Type [Vieetj] on Dvorak, expected [Việt], got [%s]", e.getPreeditString())
+	}
+	// the s of Dvorak sits on the US-QWERTY semicolon
+	var handled = typeKeys(e, []keyPress{{39, 's', 0}})
+	if handled[0] || e.getRawKeyLen() != 0 {
+		t.Errorf("Type the s of Dvorak, expected the word to be committed and the key to pass, got [%s] handled=%v", e.preeditor.GetRawString(), handled[0])
+	}
+	typeKeys(e, []keyPress{{47, 'K', IBUS_LOCK_MASK}, {30, 'A', IBUS_LOCK_MASK}})
+	if e.getPreeditString() != "VA" {
+		t.Errorf("Type [va] on Dvorak with Caps Lock, expected [VA], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestKeyCodeLayoutAzerty(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBkeyCodeLayoutEnabled)
+	// the a and q of AZERTY are swapped with US-QWERTY
+	typeKeys(e, []keyPress{{30, 'q', 0}, {30, 'q', 0}, {20, 't', 0}})
+	if e.getPreeditString() != "ât" {
+		t.Errorf("Type [aat] on AZERTY, expected [ât], got [%s]", e.getPreeditString())
+	}
+	e.preeditor.Reset()
+	// the US-QWERTY comma gives the semicolon of AZERTY, which Telex does not use
+	if keyVal, _ := e.getQwertyKeyVal(';', 51, 0); keyVal != ';' {
+		t.Errorf("Type the semicolon of AZERTY, expected [;], got [%c]", rune(keyVal))
+	}
+
+	e.config.InputMethod = "VNI"
+	e.preeditor = bamboo.NewEngine(bamboo.ParseInputMethod(e.config.InputMethodDefinitions, "VNI"), e.config.Flags)
+	// a 1 on US-QWERTY is q & on AZERTY, where the digits need Shift
+	typeKeys(e, []keyPress{{30, 'q', 0}, {2, '&', 0}})
+	if e.getPreeditString() != "á" {
+		t.Errorf("Type [a1] with VNI on AZERTY, expected [á], got [%s]", e.getPreeditString())
+	}
+	var handled = typeKeys(e, []keyPress{{2, '1', IBUS_SHIFT_MASK}})
+	if handled[0] || e.getRawKeyLen() != 0 {
+		t.Errorf("Type the 1 of AZERTY with VNI, expected the word to be committed and the key to pass, got [%s]", e.preeditor.GetRawString())
+	}
+}
+
+func TestKeyCodeLayoutColemak(t *testing.T) {
+	var e = newTestEngine(IBstdFlags | IBkeyCodeLayoutEnabled)
+	// d d o o n g s, where Colemak has s s y y k d r
+	typeKeys(e, []keyPress{{32, 's', 0}, {32, 's', 0}, {24, 'y', 0}, {24, 'y', 0}, {49, 'k', 0}, {34, 'd', 0}, {31, 'r', 0}})
+	if e.getPreeditString() != "đống" {
+		t.Errorf("Type [ddoongs] on Colemak, expected [đống], got [%s]", e.getPreeditString())
+	}
+	e.preeditor.Reset()
+	// AltGr keeps the third level of the layout
+	typeKeys(e, []keyPress{{30, 'á', IBUS_MOD5_MASK}})
+	if e.getPreeditString() == "a" {
+		t.Errorf("Type AltGr+a on Colemak, expected the character of the layout")
+	}
+}
+
+func TestKeyCodeLayoutDisabled(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	typeKeys(e, []keyPress{{30, 'q', 0}, {30, 'q', 0}})
+	if e.getPreeditString() != "qq" {
+		t.Errorf("Type [qq] on AZERTY without the option, expected [qq], got [%s]", e.getPreeditString())
+	}
+}
+
+func TestModifiedKeys(t *testing.T) {
+	var e = newTestEngine(IBstdFlags)
+	var imDef = bamboo.InputMethodDefinition{"AltGr+d": "_đ", "AltGr+[": "_ư", "s": "DauSac"}
+	e.preeditor = bamboo.NewEngine(bamboo.LookupInputMethod("AltGr", imDef), e.config.Flags)
+	// the third level of the d and [ keys on a US international layout
+	typeKeys(e, []keyPress{{32, 'ð', IBUS_MOD5_MASK}, {26, '«', IBUS_MOD5_MASK}, {31, 's', 0}})
+	if e.getPreeditString() != "đứ" {
+		t.Errorf("Type [AltGr+d AltGr+[ s], expected [đứ], got [%s]", e.getPreeditString())
+	}
+	var handled = typeKeys(e, []keyPress{{16, 'ä', IBUS_MOD5_MASK}})
+	if handled[0] || e.getRawKeyLen() != 0 {
+		t.Errorf("Type an unbound AltGr+q, expected the word to be committed and the key to pass, got [%s]", e.preeditor.GetRawString())
+	}
+}
@@ -22,11 +22,11 @@ package main
 import (
 	"flag"
 	"fmt"
-	"github.com/BambooEngine/bamboo-core"
 	"github.com/BambooEngine/goibus/ibus"
 	"github.com/godbus/dbus"
 	"log"
 	"os"
+	"strings"
 )
 
 const (
@@ -37,19 +37,43 @@ const (
 var embedded = flag.Bool("ibus", false, "Run the embedded ibus component")
 var version = flag.Bool("version", false, "Show version")
 
-func init() {
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == ConvertCommand {
+		if err := runConvert(os.Args[2:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+			if err != flag.ErrHelp {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(2)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == CompileTrieCommand {
+		if err := runCompileTrie(os.Args[2:], os.Stderr); err != nil {
+			if err != flag.ErrHelp {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(2)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == CountBigramsCommand {
+		if err := runCountBigrams(os.Args[2:], os.Stderr); err != nil {
+			if err != flag.ErrHelp {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(2)
+		}
+		return
+	}
+	// flags are parsed here rather than in init() so that `go test` can pass
+	// its own flags to the test binary
 	flag.Parse()
 	if *embedded {
 		os.Chdir(DataDir)
 	}
-	go func() {
-		emojiMap, _ = loadEmojiOne(DictEmojiOne)
-		var dictionary, _ = loadDictionary(DictVietnameseCm)
-		bamboo.AddDictionaryToSpellingTrie(dictionary)
-	}()
-}
+	store.spellingProfile = LoadConfig(strings.ToLower(EngineName)).SpellingProfile
+	go store.watch(dataReloadInterval)
 
-func main() {
 	if *version {
 		fmt.Println(Version)
 	} else if *embedded {
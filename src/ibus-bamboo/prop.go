@@ -48,6 +48,25 @@ const (
 	PropKeyAutoCapitalizeMacro         = "auto_capitalize_macro"
 	PropKeyIMQuickSwitchEnabled        = "im_quick_switch"
 	PropKeyRestoreKeyStrokes           = "restore_key_strokes"
+	PropKeyClipboardRemoveTones        = "clipboard_remove_tones"
+	PropKeyClipboardRemoveMarks        = "clipboard_remove_marks"
+	PropKeyClipboardUpperCase          = "clipboard_upper_case"
+	PropKeyClipboardLowerCase          = "clipboard_lower_case"
+	PropKeyClipboardTitleCase          = "clipboard_title_case"
+	PropKeyClipboardNewToneStyle       = "clipboard_new_tone_style"
+	PropKeyClipboardOldToneStyle       = "clipboard_old_tone_style"
+	PropKeyWordCompletion              = "word_completion"
+	PropKeyToneVariants                = "tone_variants"
+	PropKeyDiacriticRestoration        = "diacritic_restoration"
+	PropKeyNextWordPrediction          = "next_word_prediction"
+	PropKeyAddToUserDictionary         = "add_to_user_dictionary"
+	PropKeyUserDictionary              = "open_user_dictionary"
+	PropKeyEnglishDictionary           = "english_dictionary"
+	PropKeyEnglishDictionaryFile       = "open_english_dictionary"
+	PropKeySpellingProfile             = "spelling_profile::"
+	PropKeyKeyCodeLayout               = "keycode_layout"
+	PropKeyQuickTelex                  = "quick_telex"
+	PropKeyEnglishModePerWindow        = "english_mode_per_window"
 )
 
 func GetPropListByConfig(c *Config) *ibus.PropList {
@@ -95,7 +114,7 @@ func GetPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       "-",
 			Type:      ibus.PROP_TYPE_MENU,
-			Label:     dbus.MakeVariant(ibus.NewText("Kiểu gõ")),
+			Label:     dbus.MakeVariant(ibus.NewText("Kiểu gõ" + getHotKeyLabel(c, HotKeyCycleInputMethod))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("Kiểu gõ")),
 			Sensitive: true,
 			Visible:   true,
@@ -161,13 +180,39 @@ func GetCharsetPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       PropKeyVnConvert,
 			Type:      ibus.PROP_TYPE_NORMAL,
-			Label:     dbus.MakeVariant(ibus.NewText("Chuyển mã online")),
-			Tooltip:   dbus.MakeVariant(ibus.NewText("")),
+			Label:     dbus.MakeVariant(ibus.NewText("Chuyển mã clipboard" + getHotKeyLabel(c, PropKeyVnConvert))),
+			Tooltip:   dbus.MakeVariant(ibus.NewText(c.ClipboardInputCharset + " → " + c.ClipboardOutputCharset)),
 			Sensitive: true,
 			Visible:   true,
 			Symbol:    dbus.MakeVariant(ibus.NewText("C")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
+	)
+	for _, item := range []struct {
+		key   string
+		label string
+	}{
+		{PropKeyClipboardRemoveTones, "Clipboard: bỏ dấu thanh"},
+		{PropKeyClipboardRemoveMarks, "Clipboard: bỏ dấu"},
+		{PropKeyClipboardUpperCase, "Clipboard: VIẾT HOA"},
+		{PropKeyClipboardLowerCase, "Clipboard: viết thường"},
+		{PropKeyClipboardTitleCase, "Clipboard: Viết Hoa Đầu Từ"},
+		{PropKeyClipboardNewToneStyle, "Clipboard: dấu thanh kiểu mới (hòa)"},
+		{PropKeyClipboardOldToneStyle, "Clipboard: dấu thanh kiểu cũ (hoà)"},
+	} {
+		charsetProperties = append(charsetProperties, &ibus.Property{
+			Name:      "IBusProperty",
+			Key:       item.key,
+			Type:      ibus.PROP_TYPE_NORMAL,
+			Label:     dbus.MakeVariant(ibus.NewText(item.label + getHotKeyLabel(c, item.key))),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("C")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		})
+	}
+	charsetProperties = append(charsetProperties,
 		&ibus.Property{
 			Name:      "IBusProperty",
 			Key:       "-",
@@ -227,7 +272,11 @@ func GetIMPropListByConfig(c *Config) *ibus.PropList {
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
 	)
+	var imNames []string
 	for im := range c.InputMethodDefinitions {
+		imNames = append(imNames, im)
+	}
+	for _, im := range sortStrings(imNames) {
 		var state = ibus.PROP_STATE_UNCHECKED
 		if im == c.InputMethod {
 			state = ibus.PROP_STATE_CHECKED
@@ -288,7 +337,7 @@ func GetMacroPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       PropKeyMacroTable,
 			Type:      ibus.PROP_TYPE_NORMAL,
-			Label:     dbus.MakeVariant(ibus.NewText("Mở bảng gõ tắt")),
+			Label:     dbus.MakeVariant(ibus.NewText("Mở bảng gõ tắt" + getHotKeyLabel(c, PropKeyMacroTable))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("Mở bảng gõ tắt")),
 			Sensitive: true,
 			Visible:   true,
@@ -313,7 +362,11 @@ func GetSpellCheckingPropListByConfig(c *Config) *ibus.PropList {
 	if c.IBflags&IBspellCheckingWithDicts != 0 {
 		spellCheckByDicts = ibus.PROP_STATE_CHECKED
 	}
-	return ibus.NewPropList(
+	englishDictChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBenglishDictEnabled != 0 {
+		englishDictChecked = ibus.PROP_STATE_CHECKED
+	}
+	var spellingProperties = []*ibus.Property{
 		&ibus.Property{
 			Name:      "IBusProperty",
 			Key:       PropKeySpellingChecking,
@@ -361,7 +414,93 @@ func GetSpellCheckingPropListByConfig(c *Config) *ibus.PropList {
 			Symbol:    dbus.MakeVariant(ibus.NewText("O")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
-	)
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       "-",
+			Type:      ibus.PROP_TYPE_SEPARATOR,
+			Label:     dbus.MakeVariant(ibus.NewText("")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyAddToUserDictionary,
+			Type:      ibus.PROP_TYPE_NORMAL,
+			Label:     dbus.MakeVariant(ibus.NewText("Thêm từ vừa gõ vào từ điển" + getHotKeyLabel(c, PropKeyAddToUserDictionary))),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Add the last word to the user dictionary")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyUserDictionary,
+			Type:      ibus.PROP_TYPE_NORMAL,
+			Label:     dbus.MakeVariant(ibus.NewText("Mở từ điển cá nhân")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Mở từ điển cá nhân")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyEnglishDictionary,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Giữ nguyên từ tiếng Anh")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Keep English words as typed")),
+			Sensitive: true,
+			Visible:   true,
+			State:     englishDictChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyEnglishDictionaryFile,
+			Type:      ibus.PROP_TYPE_NORMAL,
+			Label:     dbus.MakeVariant(ibus.NewText("Mở từ điển tiếng Anh")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Mở từ điển tiếng Anh")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       "-",
+			Type:      ibus.PROP_TYPE_SEPARATOR,
+			Label:     dbus.MakeVariant(ibus.NewText("")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("")),
+			Sensitive: true,
+			Visible:   true,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+	}
+	for _, name := range store.getSpellingProfileNames() {
+		var state = ibus.PROP_STATE_UNCHECKED
+		if name == c.SpellingProfile {
+			state = ibus.PROP_STATE_CHECKED
+		}
+		spellingProperties = append(spellingProperties, &ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeySpellingProfile + name,
+			Type:      ibus.PROP_TYPE_RADIO,
+			Label:     dbus.MakeVariant(ibus.NewText("Luật ghép vần: " + name)),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Spelling rules profile " + name)),
+			Sensitive: true,
+			Visible:   true,
+			State:     state,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		})
+	}
+	return ibus.NewPropList(spellingProperties...)
 }
 
 func GetOptionsPropListByConfig(c *Config) *ibus.PropList {
@@ -381,12 +520,20 @@ func GetOptionsPropListByConfig(c *Config) *ibus.PropList {
 	if c.Flags&bamboo.EfreeToneMarking != 0 {
 		toneFreeMarkingChecked = ibus.PROP_STATE_CHECKED
 	}
+	quickTelexChecked := ibus.PROP_STATE_UNCHECKED
+	if c.Flags&bamboo.EquickTelex != 0 {
+		quickTelexChecked = ibus.PROP_STATE_CHECKED
+	}
 	if c.IBflags&IBpreeditInvisibility != 0 {
 		preeditInvisibilityChecked = ibus.PROP_STATE_CHECKED
 	}
 	if c.IBflags&IBfakeBackspaceEnabled != 0 {
 		x11FakeBackspaceChecked = ibus.PROP_STATE_CHECKED
 	}
+	keyCodeLayoutChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBkeyCodeLayoutEnabled != 0 {
+		keyCodeLayoutChecked = ibus.PROP_STATE_CHECKED
+	}
 
 	return ibus.NewPropList(
 		&ibus.Property{
@@ -413,6 +560,18 @@ func GetOptionsPropListByConfig(c *Config) *ibus.PropList {
 			Symbol:    dbus.MakeVariant(ibus.NewText("M")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyQuickTelex,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Gõ nhanh phụ âm (Quick Telex)")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("cc=ch, gg=gi, kk=kh, nn=ng, pp=ph, qq=qu, tt=th")),
+			Sensitive: true,
+			Visible:   true,
+			State:     quickTelexChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("Q")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
 		&ibus.Property{
 			Name:      "IBusProperty",
 			Key:       PropKeyAutoCommitWithMouseMovement,
@@ -449,6 +608,18 @@ func GetOptionsPropListByConfig(c *Config) *ibus.PropList {
 			Symbol:    dbus.MakeVariant(ibus.NewText("X")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyKeyCodeLayout,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Gõ theo vị trí phím")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Read the keys as on a US-QWERTY keyboard, whatever the layout")),
+			Sensitive: true,
+			Visible:   true,
+			State:     keyCodeLayoutChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("K")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
 	)
 }
 
@@ -461,10 +632,26 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 	if c.IBflags&IBinputModeLookupTableEnabled != 0 {
 		inputLookupTableChecked = ibus.PROP_STATE_CHECKED
 	}
+	englishModePerWindowChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBenglishModePerWindow != 0 {
+		englishModePerWindowChecked = ibus.PROP_STATE_CHECKED
+	}
 	restoreKeyStrokesChecked := ibus.PROP_STATE_UNCHECKED
 	if c.IBflags&IBrestoreKeyStrokesEnabled != 0 {
 		restoreKeyStrokesChecked = ibus.PROP_STATE_CHECKED
 	}
+	diacriticRestorationChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBdiacriticRestorationEnabled != 0 {
+		diacriticRestorationChecked = ibus.PROP_STATE_CHECKED
+	}
+	nextWordPredictionChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBnextWordPredictionEnabled != 0 {
+		nextWordPredictionChecked = ibus.PROP_STATE_CHECKED
+	}
+	wordCompletionChecked := ibus.PROP_STATE_UNCHECKED
+	if c.IBflags&IBwordCompletionEnabled != 0 {
+		wordCompletionChecked = ibus.PROP_STATE_CHECKED
+	}
 	emojiChecked := ibus.PROP_STATE_CHECKED
 	if c.IBflags&IBemojiDisabled != 0 {
 		emojiChecked = ibus.PROP_STATE_UNCHECKED
@@ -475,7 +662,7 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       PropKeyEmojiEnabled,
 			Type:      ibus.PROP_TYPE_TOGGLE,
-			Label:     dbus.MakeVariant(ibus.NewText("Emoji <Shift>:")),
+			Label:     dbus.MakeVariant(ibus.NewText("Emoji" + getHotKeyLabel(c, HotKeyOpenEmoji))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("Emoji")),
 			Sensitive: true,
 			Visible:   true,
@@ -487,7 +674,7 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       PropKeyInputModeLookupTable,
 			Type:      ibus.PROP_TYPE_TOGGLE,
-			Label:     dbus.MakeVariant(ibus.NewText("Chuyển chế độ gõ <Shift>~")),
+			Label:     dbus.MakeVariant(ibus.NewText("Chuyển chế độ gõ" + getHotKeyLabel(c, HotKeyOpenInputModeTable))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("Open Input Mode LookupTable")),
 			Sensitive: true,
 			Visible:   true,
@@ -499,7 +686,7 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 			Name:      "IBusProperty",
 			Key:       PropKeyIMQuickSwitchEnabled,
 			Type:      ibus.PROP_TYPE_TOGGLE,
-			Label:     dbus.MakeVariant(ibus.NewText("Chuyển nhanh Vi-En <Shift>")),
+			Label:     dbus.MakeVariant(ibus.NewText("Chuyển nhanh Vi-En" + getHotKeyLabel(c, HotKeyToggleEnglishMode))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("IM quick switch")),
 			Sensitive: true,
 			Visible:   true,
@@ -507,11 +694,23 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 			Symbol:    dbus.MakeVariant(ibus.NewText("")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyEnglishModePerWindow,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Nhớ chế độ Vi-En theo cửa sổ")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Remember the Vi-En mode of each window instead of each application")),
+			Sensitive: true,
+			Visible:   true,
+			State:     englishModePerWindowChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
 		&ibus.Property{
 			Name:      "IBusProperty",
 			Key:       PropKeyRestoreKeyStrokes,
 			Type:      ibus.PROP_TYPE_TOGGLE,
-			Label:     dbus.MakeVariant(ibus.NewText("Khôi phục phím <Shift><Space>")),
+			Label:     dbus.MakeVariant(ibus.NewText("Khôi phục phím" + getHotKeyLabel(c, HotKeyRestoreWord))),
 			Tooltip:   dbus.MakeVariant(ibus.NewText("Restore key strokes")),
 			Sensitive: true,
 			Visible:   true,
@@ -519,5 +718,41 @@ func GetHotKeyPropListByConfig(c *Config) *ibus.PropList {
 			Symbol:    dbus.MakeVariant(ibus.NewText("")),
 			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
 		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyWordCompletion,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Gợi ý từ <Tab>" + getHotKeyLabel(c, PropKeyWordCompletion))),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Word completion")),
+			Sensitive: true,
+			Visible:   true,
+			State:     wordCompletionChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyNextWordPrediction,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Gợi ý từ tiếp theo <Tab>")),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Next word prediction")),
+			Sensitive: true,
+			Visible:   true,
+			State:     nextWordPredictionChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
+		&ibus.Property{
+			Name:      "IBusProperty",
+			Key:       PropKeyDiacriticRestoration,
+			Type:      ibus.PROP_TYPE_TOGGLE,
+			Label:     dbus.MakeVariant(ibus.NewText("Gõ không dấu, chọn từ có dấu" + getHotKeyLabel(c, PropKeyDiacriticRestoration))),
+			Tooltip:   dbus.MakeVariant(ibus.NewText("Diacritic restoration")),
+			Sensitive: true,
+			Visible:   true,
+			State:     diacriticRestorationChecked,
+			Symbol:    dbus.MakeVariant(ibus.NewText("")),
+			SubProps:  dbus.MakeVariant(*ibus.NewPropList()),
+		},
 	)
 }
@@ -0,0 +1,89 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+const DefaultSpellingProfile = "default"
+
+func loadSpellingRulesFile(fileName string) (map[string]bamboo.SpellingRules, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := bamboo.ParseSpellingRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return profiles, nil
+}
+
+// mergeSpellingProfiles loads the system profiles and then the user ones, a
+// profile of the user replaces the system one of the same name. The built-in
+// rules are always available as the default profile.
+func mergeSpellingProfiles(systemFile, userFile string) map[string]bamboo.SpellingRules {
+	var profiles = map[string]bamboo.SpellingRules{
+		DefaultSpellingProfile: bamboo.DefaultSpellingRules,
+	}
+	systemProfiles, err := loadSpellingRulesFile(systemFile)
+	if err != nil {
+		log.Println(err)
+	}
+	for name, rules := range systemProfiles {
+		profiles[name] = rules
+	}
+	userProfiles, err := loadSpellingRulesFile(userFile)
+	if err != nil && !os.IsNotExist(err) {
+		log.Println(err)
+	}
+	for name, rules := range userProfiles {
+		profiles[name] = rules
+	}
+	return profiles
+}
+
+func getSpellingRulesFile(engineName string) string {
+	return fmt.Sprintf(spellingRulesFile, getConfigDir(), engineName)
+}
+
+// getSpellingRules returns the rules of the profile, the default rules are
+// used when the profile is unknown.
+func getSpellingRules(profiles map[string]bamboo.SpellingRules, name string) bamboo.SpellingRules {
+	var rules, found = profiles[name]
+	if !found {
+		log.Printf("unknown spelling profile %q, using %q", name, DefaultSpellingProfile)
+		return bamboo.DefaultSpellingRules
+	}
+	return rules
+}
+
+func getSpellingProfileFromPropKey(propKey string) (string, bool) {
+	if strings.HasPrefix(propKey, PropKeySpellingProfile) {
+		return strings.TrimPrefix(propKey, PropKeySpellingProfile), true
+	}
+	return "", false
+}
@@ -0,0 +1,93 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSystemSpellingProfiles(t *testing.T) {
+	var profiles, err = loadSpellingRulesFile("../../" + SpellingRulesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(profiles[DefaultSpellingProfile], bamboo.DefaultSpellingRules) {
+		t.Errorf("Profile %s of %s, expected the built-in spelling rules", DefaultSpellingProfile, SpellingRulesFile)
+	}
+	var tests = []struct {
+		profile  string
+		word     string
+		expected bool
+	}{
+		{"strict", "za", false},
+		{"strict", "ba", true},
+		{"loanword-friendly", "fan", true},
+		{"loanword-friendly", "blôc", true},
+		{"ethnic-names", "krôm", true},
+		{"ethnic-names", "đăk", true},
+		{"ethnic-names", "pơng", true},
+		{DefaultSpellingProfile, "krôm", false},
+	}
+	for _, test := range tests {
+		var rules, found = profiles[test.profile]
+		if !found {
+			t.Errorf("Profile %s, expected in %s", test.profile, SpellingRulesFile)
+			continue
+		}
+		if containsWord(rules.GenerateWords(), test.word) != test.expected {
+			t.Errorf("Profile %s, word %s, expected [%v]", test.profile, test.word, test.expected)
+		}
+	}
+}
+
+func TestMergeSpellingProfiles(t *testing.T) {
+	var dir = t.TempDir()
+	var userFile = filepath.Join(dir, "ibus-bamboo.spelling_rules.json")
+	var userProfiles = `{"version": 1, "profiles": {"strict": {
+		"first_consonants": ["b"], "vowels": ["a"], "last_consonants": ["n"],
+		"cv_matrix": [[0]], "vc_matrix": [[0]]}}}`
+	if err := ioutil.WriteFile(userFile, []byte(userProfiles), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var profiles = mergeSpellingProfiles("../../"+SpellingRulesFile, userFile)
+	if words := profiles["strict"].GenerateWords(); len(words) != 4 {
+		t.Errorf("Merge profiles, expected the user's strict profile, got %q", words)
+	}
+	if _, found := profiles["ethnic-names"]; !found {
+		t.Errorf("Merge profiles, expected the system ethnic-names profile")
+	}
+	profiles = mergeSpellingProfiles(filepath.Join(dir, "system.json"), filepath.Join(dir, "user.json"))
+	if len(profiles) != 1 || !reflect.DeepEqual(profiles[DefaultSpellingProfile], bamboo.DefaultSpellingRules) {
+		t.Errorf("Merge profiles without files, expected only the built-in rules, got %d profiles", len(profiles))
+	}
+}
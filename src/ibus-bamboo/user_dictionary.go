@@ -0,0 +1,121 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/BambooEngine/bamboo-core"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// getUserDictionaryFile is replaced in tests
+var getUserDictionaryFile = func(engineName string) string {
+	return fmt.Sprintf(userDictFile, getConfigDir(), engineName)
+}
+
+func loadUserDictionary(engineName string) map[string]bool {
+	var words, err = loadDictionary(getUserDictionaryFile(engineName))
+	if err != nil {
+		return map[string]bool{}
+	}
+	return words
+}
+
+func saveUserDictionary(fileName string, words map[string]bool) error {
+	var list = make([]string, 0, len(words))
+	for word := range words {
+		list = append(list, word)
+	}
+	sort.Strings(list)
+	return writeFileAtomically(fileName, []byte(strings.Join(list, "\n")+"\n"))
+}
+
+// addToUserDictionary makes the spell checking accept the word from now on.
+func addToUserDictionary(engineName string, word string) error {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return nil
+	}
+	var words, added = store.addUserWord(word)
+	if !added {
+		return nil
+	}
+	var fileName = getUserDictionaryFile(engineName)
+	if err := saveUserDictionary(fileName, words); err != nil {
+		return err
+	}
+	store.touch(fileName)
+	return nil
+}
+
+func OpenUserDictionaryFile(engineName string) {
+	var fileName = getUserDictionaryFile(engineName)
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		ioutil.WriteFile(fileName, nil, 0644)
+	}
+	exec.Command("xdg-open", fileName).Start()
+}
+
+// getLastTypedWord returns the Vietnamese word being composed or, if there is
+// none, the last one committed.
+func (e *IBusBambooEngine) getLastTypedWord() string {
+	if vnSeq := e.getProcessedString(bamboo.VietnameseMode); vnSeq != "" {
+		return vnSeq
+	}
+	return e.lastWord
+}
+
+func (e *IBusBambooEngine) addLastWordToUserDictionary() {
+	var word = e.getLastTypedWord()
+	if !bamboo.HasVietnameseChar(word) {
+		return
+	}
+	if err := addToUserDictionary(e.engineName, word); err != nil {
+		log.Println("Failed to save the user dictionary:", err)
+	}
+	if e.getRawKeyLen() > 0 {
+		e.updatePreedit(e.getPreeditString())
+	}
+}
+
+// learnRestoredWord counts how many times the user kept with Shift+Space a word
+// which the auto-restore would revert, the word is added to the user dictionary
+// after Config.LearnWordAfter times.
+func (e *IBusBambooEngine) learnRestoredWord(word string) {
+	if e.config.LearnWordAfter <= 0 || e.isContentPrivate() {
+		return
+	}
+	if e.restoreCounts == nil {
+		e.restoreCounts = map[string]int{}
+	}
+	word = strings.ToLower(word)
+	e.restoreCounts[word]++
+	if e.restoreCounts[word] >= e.config.LearnWordAfter {
+		delete(e.restoreCounts, word)
+		if err := addToUserDictionary(e.engineName, word); err != nil {
+			log.Println("Failed to save the user dictionary:", err)
+		}
+	}
+}
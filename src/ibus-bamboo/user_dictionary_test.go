@@ -0,0 +1,91 @@
+/*
+ * Bamboo - A Vietnamese Input method editor
+ * Copyright (C) 2018 Luong Thanh Lam <ltlam93@gmail.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withUserDictionaryFile makes the user dictionary empty and be saved to a
+// temporary file until the test ends.
+func withUserDictionaryFile(t *testing.T) string {
+	var fileName = withTestFile(t, &getUserDictionaryFile, "ibus-bamboo.user.dict")
+	var saved = store.data.userDictionary
+	store.data.userDictionary = map[string]bool{}
+	t.Cleanup(func() {
+		store.data.userDictionary = saved
+	})
+	return fileName
+}
+
+func TestSaveUserDictionary(t *testing.T) {
+	var fileName = withUserDictionaryFile(t)
+	var words = map[string]bool{"hưởng": true, "xoá": true}
+	if err := saveUserDictionary(fileName, words); err != nil {
+		t.Fatal(err)
+	}
+	if loaded := loadUserDictionary("bamboo"); !reflect.DeepEqual(loaded, words) {
+		t.Errorf("Load the saved dictionary, got %v", loaded)
+	}
+	var files, _ = ioutil.ReadDir(filepath.Dir(fileName))
+	if len(files) != 1 {
+		t.Errorf("Save the dictionary, expected no temporary file to be left, got %d files", len(files))
+	}
+}
+
+func TestLearnRestoredWord(t *testing.T) {
+	withReadyStore(t)
+	var fileName = withUserDictionaryFile(t)
+	var e = newTestEngine(IBstdFlags | IBspellCheckingWithDicts | IBrestoreKeyStrokesEnabled)
+	e.config.LearnWordAfter = 2
+	for i := 0; i < 2; i++ {
+		typeString(e, "khuyr")
+		if e.getComposedString() != "khuyr" {
+			t.Fatalf("Process [khuyr] with the dictionary, expected it to be reverted, got [%s]", e.getComposedString())
+		}
+		e.ProcessKeyEvent(IBUS_Space, 0, IBUS_SHIFT_MASK)
+		if e.getRawKeyLen() != 0 || e.lastWord != "khủy" {
+			t.Errorf("Keep [khủy] with Shift+Space, expected it to be committed, got [%s]", e.lastWord)
+		}
+	}
+	if !store.data.userDictionary["khủy"] {
+		t.Errorf("Keep [khủy] twice, expected it in the user dictionary")
+	}
+	if loaded, _ := loadDictionary(fileName); !loaded["khủy"] {
+		t.Errorf("Keep [khủy] twice, expected it in the user dictionary file")
+	}
+	typeString(e, "khuyr")
+	if e.getComposedString() != "khủy" {
+		t.Errorf("Process [khuyr] after learning, expected [khủy], got [%s]", e.getComposedString())
+	}
+}
+
+func TestAddLastWordToUserDictionary(t *testing.T) {
+	withUserDictionaryFile(t)
+	var e = newTestEngine(IBstdFlags)
+	typeString(e, "nguyeenx ")
+	e.addLastWordToUserDictionary()
+	if !store.data.userDictionary["nguyễn"] {
+		t.Errorf("Add the last word, expected [nguyễn] in the user dictionary, got %v", store.data.userDictionary)
+	}
+}
@@ -40,19 +40,30 @@ const (
 	VnCaseNoChange
 )
 const (
-	HomePage           = "https://github.com/BambooEngine/ibus-bamboo"
-	CharsetConvertPage = "https://tools.jcisio.com/vietuni/"
-
-	DataDir          = "/usr/share/ibus-bamboo"
-	DictVietnameseCm = "data/vietnamese.cm.dict"
-	DictEmojiOne     = "data/emojione.json"
+	HomePage = "https://github.com/BambooEngine/ibus-bamboo"
+
+	DataDir              = "/usr/share/ibus-bamboo"
+	DictVietnameseCm     = "data/vietnamese.cm.dict"
+	DictVietnameseCmTrie = "data/vietnamese.cm.trie"
+	DictEmojiOne         = "data/emojione.json"
+	DictVnPhrases        = "data/vietnamese.phrase.dict"
+	DictVnBigrams        = "data/vietnamese.bigram"
+	DictEnglish          = "data/english.dict"
+	SpellingRulesFile    = "data/spelling_rules.json"
+	InputMethodFile      = "data/input_method.json"
 )
 
 const (
-	configDir        = "%s/.config/ibus-bamboo"
-	configFile       = "%s/ibus-%s.config.json"
-	mactabFile       = "%s/ibus-%s.macro.text"
-	sampleMactabFile = "data/macro.tpl.txt"
+	configDir         = "%s/.config/ibus-bamboo"
+	configFile        = "%s/ibus-%s.config.json"
+	mactabFile        = "%s/ibus-%s.macro.text"
+	inputMethodFile   = "%s/ibus-%s.input_method.json"
+	userDictFile      = "%s/ibus-%s.user.dict"
+	learnedDictFile   = "%s/ibus-%s.learned.dict"
+	englishDictFile   = "%s/ibus-%s.english.dict"
+	spellingRulesFile = "%s/ibus-%s.spelling_rules.json"
+	englishModeFile   = "%s/ibus-%s.english_mode.json"
+	sampleMactabFile  = "data/macro.tpl.txt"
 )
 
 const (
@@ -74,6 +85,12 @@ const (
 	IBautoCapitalizeMacro
 	IBimQuickSwitchEnabled
 	IBrestoreKeyStrokesEnabled
+	IBwordCompletionEnabled
+	IBdiacriticRestorationEnabled
+	IBnextWordPredictionEnabled
+	IBenglishDictEnabled
+	IBkeyCodeLayoutEnabled
+	IBenglishModePerWindow
 	IBstdFlags = IBspellChecking | IBspellCheckingWithRules | IBautoNonVnRestore | IBddFreeStyle |
 		IBpreeditInvisibility | IBautoCommitWithMouseMovement | IBemojiDisabled | IBinputModeLookupTableEnabled
 )
@@ -86,11 +103,19 @@ var DefaultBrowserList = []string{
 
 type Config struct {
 	InputMethod               string
-	InputMethodDefinitions    map[string]bamboo.InputMethodDefinition
+	InputMethodDefinitions    map[string]bamboo.InputMethodDefinition `json:"-"`
 	OutputCharset             string
+	ClipboardInputCharset     string
+	ClipboardOutputCharset    string
 	Flags                     uint
 	IBflags                   uint
 	AutoCommitAfter           int64
+	LearnWordAfter            int
+	SpellingProfile           string
+	HotKeys                   map[string]string
+	ContentTypePolicies       map[string]string
+	AppProfiles               map[string]AppProfile
+	NeverTransformList        []string
 	ExceptedList              []string
 	PreeditWhiteList          []string
 	X11ClipboardWhiteList     []string
@@ -123,10 +148,17 @@ func LoadConfig(engineName string) *Config {
 	var c = Config{
 		InputMethod:               "Telex",
 		OutputCharset:             "Unicode",
-		InputMethodDefinitions:    bamboo.InputMethodDefinitions,
+		ClipboardInputCharset:     "TCVN3 (ABC)",
+		ClipboardOutputCharset:    "Unicode",
 		Flags:                     bamboo.EstdFlags,
 		IBflags:                   IBstdFlags,
 		AutoCommitAfter:           3000,
+		LearnWordAfter:            3,
+		SpellingProfile:           DefaultSpellingProfile,
+		HotKeys:                   map[string]string{},
+		ContentTypePolicies:       getDefaultContentTypePolicies(),
+		AppProfiles:               map[string]AppProfile{},
+		NeverTransformList:        nil,
 		ExceptedList:              nil,
 		PreeditWhiteList:          nil,
 		X11ClipboardWhiteList:     nil,
@@ -139,7 +171,14 @@ func LoadConfig(engineName string) *Config {
 	data, err := ioutil.ReadFile(getConfigPath(engineName))
 	if err == nil {
 		json.Unmarshal(data, &c)
+		var legacy struct {
+			InputMethodDefinitions InputMethodDefinitions
+		}
+		if json.Unmarshal(data, &legacy) == nil {
+			migrateInputMethodDefinitions(legacy.InputMethodDefinitions, getInputMethodFile(engineName))
+		}
 	}
+	c.InputMethodDefinitions = getInputMethodDefinitions(engineName)
 
 	return &c
 }
@@ -157,6 +196,30 @@ func SaveConfig(c *Config, engineName string) {
 
 }
 
+// writeFileAtomically writes the data to a temporary file and renames it, so
+// that the file is never left half written.
+func writeFileAtomically(fileName string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	// the temporary file is private, the file it replaces is not
+	if err = f.Chmod(0644); err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), fileName)
+}
+
 func getEngineSubFile(fileName string) string {
 	if _, err := os.Stat(fileName); err == nil {
 		if absPath, err := filepath.Abs(fileName); err == nil {
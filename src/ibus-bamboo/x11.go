@@ -25,6 +25,7 @@ package main
 #include <stdlib.h>
 
 extern void x11Copy(char*);
+extern char* x11GetClipboard();
 extern void x11Paste(int);
 extern void clipboard_init();
 extern void clipboard_exit();
@@ -39,6 +40,7 @@ extern void x11SendShiftR();
 extern void x11SendShiftLeft(int n, int r, int timeout);
 extern void setXIgnoreErrorHandler();
 extern char* x11GetFocusWindowClass();
+extern unsigned long x11GetFocusWindow();
 */
 import "C"
 import (
@@ -82,6 +84,15 @@ func x11Copy(str string) {
 	C.x11Copy(cs)
 }
 
+func x11GetClipboard() string {
+	var text = C.x11GetClipboard()
+	if text != nil {
+		defer C.free(unsafe.Pointer(text))
+		return C.GoString(text)
+	}
+	return ""
+}
+
 func x11ClipboardInit() {
 	C.clipboard_init()
 }
@@ -122,3 +133,7 @@ func x11GetFocusWindowClass() string {
 	}
 	return ""
 }
+
+func x11GetFocusWindow() uint32 {
+	return uint32(C.x11GetFocusWindow())
+}
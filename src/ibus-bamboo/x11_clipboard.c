@@ -22,11 +22,16 @@
 #include <pthread.h>
 #include <stdlib.h>
 #include <stdio.h>
+#include <unistd.h> // usleep
 #define MAX_TEXT_LEN 100
 
 static pthread_t th_clipboard;
 static int clipboard_running;
 static char * text = NULL;
+static size_t text_size = 0;
+/* text_mutex guards text and text_size, the selection owner threads read the
+ * text while x11Copy may grow it */
+static pthread_mutex_t text_mutex = PTHREAD_MUTEX_INITIALIZER;
 static char * old_text = NULL;
 static int done = 0;
 
@@ -72,7 +77,11 @@ static void* thread_clipboard_copy(void* data) {
                     XSendEvent (display, ev.requestor, 0, 0, (XEvent *)&ev);
                     break;
                 }
-                if (text == NULL) break;
+                pthread_mutex_lock(&text_mutex);
+                if (text == NULL) {
+                    pthread_mutex_unlock(&text_mutex);
+                    break;
+                }
                 int size = strlen(text);
                 if (ev.target == targets_atom) {
                     R = XChangeProperty (ev.display, ev.requestor, ev.property, XA_ATOM, 32, PropModeReplace, (unsigned char*)&UTF8, 1);
@@ -86,6 +95,7 @@ static void* thread_clipboard_copy(void* data) {
                     done = 1;
                 }
                 else ev.property = None;
+                pthread_mutex_unlock(&text_mutex);
                 if ((R & 2) == 0) XSendEvent (display, ev.requestor, 0, 0, (XEvent *)&ev);
                 break;
             case SelectionClear:
@@ -112,21 +122,77 @@ void clipboard_init()
     }
 }
 
-void x11ClipboardReset() {
-    if (text == NULL) {
-        text = (char*)calloc(MAX_TEXT_LEN, sizeof(char));
+/* ensure_text_size must be called with text_mutex held */
+static void ensure_text_size(size_t size) {
+    if (size < MAX_TEXT_LEN) {
+        size = MAX_TEXT_LEN;
     }
+    if (text == NULL || size > text_size) {
+        text = (char*)realloc(text, size);
+        text_size = size;
+    }
+}
+
+void x11ClipboardReset() {
+    pthread_mutex_lock(&text_mutex);
+    ensure_text_size(1);
     strcpy(text, "");
+    pthread_mutex_unlock(&text_mutex);
 }
 
 void x11Copy(char *str) {
-    if (text == NULL) {
-        text = (char*)calloc(MAX_TEXT_LEN, sizeof(char));
-    }
+    pthread_mutex_lock(&text_mutex);
+    ensure_text_size(strlen(str) + 1);
     strcpy(text, str);
     done = 0;
-    fprintf(stderr, "...x11Clipboard text=%s, clipboard_running=%d\n", text, clipboard_running);
+    pthread_mutex_unlock(&text_mutex);
+    /* the text may be a whole document, only its length is logged */
+    fprintf(stderr, "...x11Clipboard len=%zu, clipboard_running=%d\n", strlen(str), clipboard_running);
     if (clipboard_running == 0) {
         clipboard_init();
     }
 }
+
+/* x11GetClipboard returns a copy of the CLIPBOARD selection in UTF-8, the
+ * caller must free it. NULL is returned if the owner does not answer in time. */
+char* x11GetClipboard() {
+    Display* display = XOpenDisplay(0);
+    if (!display) {
+        return NULL;
+    }
+    int N = DefaultScreen(display);
+    Window window = XCreateSimpleWindow(display, RootWindow(display, N), 0, 0, 1, 1, 0,
+        BlackPixel(display, N), WhitePixel(display, N));
+    Atom selection = XInternAtom(display, "CLIPBOARD", 0);
+    Atom utf8 = XInternAtom(display, "UTF8_STRING", 0);
+    Atom property = XInternAtom(display, "BAMBOO_CLIPBOARD", 0);
+    XConvertSelection(display, selection, utf8, property, window, CurrentTime);
+    XFlush(display);
+
+    char *result = NULL;
+    XEvent event;
+    for (int i = 0; i < 100; i++) {
+        if (!XCheckTypedWindowEvent(display, window, SelectionNotify, &event)) {
+            usleep(10000);
+            continue;
+        }
+        if (event.xselection.property == None) {
+            break;
+        }
+        Atom type;
+        int format;
+        unsigned long nitems, bytes_after;
+        unsigned char *data = NULL;
+        if (XGetWindowProperty(display, window, property, 0, ~0L, True, AnyPropertyType,
+                &type, &format, &nitems, &bytes_after, &data) == Success && data != NULL) {
+            if (type == utf8 || type == XA_STRING) {
+                result = strndup((char*)data, nitems);
+            }
+            XFree(data);
+        }
+        break;
+    }
+    XDestroyWindow(display, window);
+    XCloseDisplay(display);
+    return result;
+}
@@ -59,14 +59,15 @@ char * x11GetStringProperty(Display *display, Window window, char * propName) {
     return NULL;
 }
 
-char * x11GetFocusWindowClasses(Display *display, char * propName) {
+// x11FindFocusWindow returns the focused window, or the parent of it, which has the property
+Window x11FindFocusWindow(Display *display, char * propName, char ** strClass) {
     Window w;
     int revertTo;
     XGetInputFocus(display, &w, &revertTo);
     for (int i=0; i<MaxWmClassesLen; i++) {
-        char * strClass = x11GetStringProperty(display, w, propName);
-        if (strClass != NULL && strstr(strClass, "FocusProxy") == NULL) {
-            return strClass;
+        *strClass = x11GetStringProperty(display, w, propName);
+        if (*strClass != NULL && strstr(*strClass, "FocusProxy") == NULL) {
+            return w;
         }
         Window * childrenWindows;
         Window parentWindow, rootWindow;
@@ -80,7 +81,14 @@ char * x11GetFocusWindowClasses(Display *display, char * propName) {
         }
         w = parentWindow;
     }
-    return NULL;
+    *strClass = NULL;
+    return None;
+}
+
+char * x11GetFocusWindowClasses(Display *display, char * propName) {
+    char * strClass = NULL;
+    x11FindFocusWindow(display, propName, &strClass);
+    return strClass;
 }
 
 char * x11GetFocusWindowClass() {
@@ -95,3 +103,17 @@ char * x11GetFocusWindowClass() {
     XCloseDisplay(display);
     return strClass;
 }
+
+unsigned long x11GetFocusWindow() {
+    Display *display = XOpenDisplay(NULL);
+    if (!display) {
+        return None;
+    }
+    char * strClass = NULL;
+    Window w = x11FindFocusWindow(display, WM_CLASS, &strClass);
+    if (strClass != NULL) {
+        XFree(strClass);
+    }
+    XCloseDisplay(display);
+    return w;
+}